@@ -11,7 +11,7 @@ from pydantic import BaseModel, Field
 
 class Node(BaseModel):
     id: str = Field(description="Unique identifier for the node. Use 'start' for the start node and 'end' for the end node.")
-    type: str = Field(description="Type of the node. Options: 'Start', 'End', 'Move', 'Standup', 'Sitdown', 'TTS', 'Head'.")
+    type: str = Field(description="Type of the node. Options: 'Start', 'End', 'Move', 'Standup', 'Standdown', 'Sitdown', 'TTS', 'Head'.")
     params: Dict[str, Any] = Field(default_factory=dict, description="Parameters for the node (e.g., x, y, orientation).")
     transitions: Dict[str, str] = Field(default_factory=dict, description="Dictionary of transitions, e.g., {'next': 'target_node_id'}.")
 
@@ -38,6 +38,7 @@ The flow chart nodes are included below:
 - End: The exit point. id="end". No transitions.
 - Move: A movement command. Params: x (float), y (float), orientation (float). Transition "next" points to the next node.
 - Standup: Command to stand up. No params. Transition "next" points to the next node.
+- Standdown: Command to lie down. No params. Transition "next" points to the next node.
 - Sitdown: Command to sit down. No params. Transition "next" points to the next node.
 - TTS: Command to speak a message. Params: text (string). Transition "next" points to the next node.
 - Head: Command to move head. Params: angle(float). Transition "next" points to the next node.
@@ -1,42 +1,104 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/chungweeeei/Temporal-robot-project/internal/api"
+	"github.com/chungweeeei/Temporal-robot-project/internal/api/handlers"
+	"github.com/chungweeeei/Temporal-robot-project/internal/audit"
+	"github.com/chungweeeei/Temporal-robot-project/internal/auth"
 	config "github.com/chungweeeei/Temporal-robot-project/internal/config/api"
+	"github.com/chungweeeei/Temporal-robot-project/internal/config/settings"
 	"github.com/chungweeeei/Temporal-robot-project/internal/database"
+	"github.com/chungweeeei/Temporal-robot-project/internal/logging"
+	"github.com/chungweeeei/Temporal-robot-project/internal/metrics"
+	"github.com/chungweeeei/Temporal-robot-project/internal/temporalclient"
+	"github.com/chungweeeei/Temporal-robot-project/internal/tracing"
 	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/workflow"
 )
 
 func main() {
 
+	createAPIKey := flag.String("create-api-key", "", "create an API key with the given name, print it and exit")
+	apiKeyRoles := flag.String("api-key-roles", string(auth.RoleAdmin), "comma separated roles of the key created with -create-api-key")
+
+	// Settings from -config file, environment and flags
+	settingsConfig, printConfig, err := settings.Load(flag.CommandLine, os.Args[1:], settings.RESTServer)
+	if err != nil {
+		log.Fatalln("Unable to load settings:", err)
+	}
+	if printConfig {
+		settingsConfig.Print(os.Stdout)
+		return
+	}
+	logger := logging.Setup(settingsConfig.Log, settings.RESTServer)
+
 	// Initialize database connection
-	db := database.InitDB()
+	db, err := database.InitDB(settingsConfig.Database, settingsConfig.Timezone)
+	if err != nil {
+		logger.Error("Unable to initialize database", "error", err)
+		os.Exit(1)
+	}
+
+	// Bootstrap the first key, every endpoint requires authentication
+	if *createAPIKey != "" {
+		key, err := handlers.NewAPIKey(database.New(db).APIKey, *createAPIKey, strings.Split(*apiKeyRoles, ","), 0)
+		if err != nil {
+			logger.Error("Unable to create API key", "error", err)
+			os.Exit(1)
+		}
+		fmt.Printf("API key %s (%s) created, it is shown only once:\n%s\n", key.Name, key.ID, key.Key)
+		return
+	}
+
+	// Trace requests through Temporal down to the robot
+	shutdownTracing, err := tracing.Setup(settingsConfig.Tracing, string(settings.RESTServer))
+	if err != nil {
+		logger.Error("Unable to set up tracing", "error", err)
+		os.Exit(1)
+	}
+	tracingInterceptor, err := tracing.TemporalInterceptor()
+	if err != nil {
+		logger.Error("Unable to create tracing interceptor", "error", err)
+		os.Exit(1)
+	}
 
 	// Initialize Temporal client
-	temporalClient, err := client.Dial(client.Options{
-		HostPort: "localhost:7233",
+	temporalClient, err := temporalclient.Dial(settingsConfig.Temporal, client.Options{
+		// the caller of each REST request is recorded in the workflow history
+		ContextPropagators: []workflow.ContextPropagator{audit.NewActorPropagator(), logging.NewPropagator()},
+		MetricsHandler:     metrics.NewTemporalHandler(),
+		Interceptors:       []interceptor.ClientInterceptor{tracingInterceptor},
+		Logger:             logging.TemporalLogger(logger),
 	})
 	if err != nil {
-		log.Fatalf("Unable to create Temporal client: %v", err)
+		logger.Error("Unable to create Temporal client", "error", err)
+		os.Exit(1)
 	}
 
 	// Register restful server
-	app := config.NewAppConfig(db, temporalClient)
+	app := config.NewAppConfig(db, temporalClient, settingsConfig, logger)
 
 	go listenForErrors(app)
 
-	go listenForShutdown(app)
+	go listenForShutdown(app, shutdownTracing)
 
 	router := api.NewRouter(app)
 
-	app.InfoLog.Println("Starting REST server on :3000")
-	if err := router.Run("localhost:3000"); err != nil {
-		log.Panic(err)
+	app.Logger.Info("Starting REST server", "listen", settingsConfig.REST.Listen)
+	if err := router.Run(settingsConfig.REST.Listen); err != nil {
+		app.Logger.Error("REST server stopped", "error", err)
+		os.Exit(1)
 	}
 }
 
@@ -45,18 +107,25 @@ func listenForErrors(app *config.AppConfig) {
 	for {
 		select {
 		case err := <-app.ErrorChan:
-			app.ErrorLog.Println(err)
+			app.Logger.Error("Background task failed", "error", err)
 		case <-app.ErrorDoneChan:
 			return
 		}
 	}
 }
 
-func listenForShutdown(app *config.AppConfig) {
+func listenForShutdown(app *config.AppConfig, shutdownTracing func(context.Context) error) {
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	app.Shutdown()
+
+	// flush the spans still batched
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		app.Logger.Error("Unable to flush spans", "error", err)
+	}
 	os.Exit(0)
 }
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/chungweeeei/Temporal-robot-project/internal/config/settings"
+	"github.com/chungweeeei/Temporal-robot-project/internal/logging"
+	"github.com/chungweeeei/Temporal-robot-project/internal/robot/recording"
+)
+
+const usage = `Usage:
+  robot-recorder record -robot ws://192.168.1.10:9090 [-listen localhost:9190] [-out session.jsonl]
+      proxy the worker to the robot and record the rosbridge traffic,
+      point the worker at it with ROBOT_URL=ws://localhost:9190/
+  robot-recorder replay -session session.jsonl [-listen localhost:9090] [-speed 1]
+      stand in for the robot and serve a recorded session`
+
+const component settings.Component = "robot-recorder"
+
+func main() {
+
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "record":
+		record(os.Args[2:])
+	case "replay":
+		replay(os.Args[2:])
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func record(args []string) {
+
+	flags := flag.NewFlagSet("record", flag.ExitOnError)
+	robotURL := flags.String("robot", "", "websocket URL of the robot")
+	listen := flags.String("listen", "localhost:9190", "address the worker connects to")
+	out := flags.String("out", "session.jsonl", "session file to write")
+	logConfig := logFlags(flags)
+	flags.Parse(args)
+	logging.Setup(*logConfig, component)
+
+	if *robotURL == "" {
+		fatal("-robot is required")
+	}
+
+	session, err := recording.NewWriter(*out)
+	if err != nil {
+		fatal("Unable to create session file", "error", err)
+	}
+
+	// flush the session file on Ctrl+C
+	go func() {
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+		<-sigCh
+		session.Close()
+		slog.Info("Recording saved", "file", *out)
+		os.Exit(0)
+	}()
+
+	proxy := &recording.Proxy{Upstream: *robotURL, Session: session}
+	slog.Info("Recording started", "robot", *robotURL, "file", *out, "listen", *listen)
+	fatal("Recorder stopped", "error", http.ListenAndServe(*listen, proxy))
+}
+
+func replay(args []string) {
+
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	sessionPath := flags.String("session", "", "session file to replay")
+	listen := flags.String("listen", "localhost:9090", "address the worker connects to")
+	speed := flags.Float64("speed", 1, "replay speed factor, 0 sends recorded messages without delay")
+	logConfig := logFlags(flags)
+	flags.Parse(args)
+	logging.Setup(*logConfig, component)
+
+	if *sessionPath == "" {
+		fatal("-session is required")
+	}
+	if *speed < 0 {
+		fatal("-speed must not be negative")
+	}
+
+	session, err := recording.LoadSession(*sessionPath)
+	if err != nil {
+		fatal("Unable to load session", "error", err)
+	}
+
+	replayer := recording.NewReplayer(session, *speed)
+	slog.Info("Replay started", "file", *sessionPath, "messages", len(session.Messages), "listen", *listen)
+	fatal("Replayer stopped", "error", http.ListenAndServe(*listen, replayer))
+}
+
+// logFlags adds the log output flags, the recorder reads no settings file.
+func logFlags(flags *flag.FlagSet) *settings.LogConfig {
+	config := settings.Default().Log
+	flags.StringVar(&config.Format, "log-format", config.Format, "log output, text or json")
+	flags.StringVar(&config.Level, "log-level", config.Level, "minimum log level: debug, info, warn or error")
+	return &config
+}
+
+// fatal logs the error and exits, like log.Fatal for the slog output.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
@@ -1,18 +1,163 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
 
+	"github.com/chungweeeei/Temporal-robot-project/internal/config/settings"
+	"github.com/chungweeeei/Temporal-robot-project/internal/logging"
 	"github.com/chungweeeei/Temporal-robot-project/internal/robot/client"
 	"github.com/chungweeeei/Temporal-robot-project/internal/robot/simulator"
 )
 
 func main() {
-	robotSim := simulator.NewMockRobot()
-	robotHandler := client.NewRobotHandler(robotSim)
 
-	http.HandleFunc("/", robotHandler.HandleWS)
-	log.Println("Mock Robot Server started on :9090")
-	http.ListenAndServe("localhost:9090", nil)
+	opts := simulator.DefaultOptions()
+	flag.Float64Var(&opts.Kinematics.LinearSpeed, "linear-speed", opts.Kinematics.LinearSpeed, "maximum linear speed (m/s)")
+	flag.Float64Var(&opts.Kinematics.AngularSpeed, "angular-speed", opts.Kinematics.AngularSpeed, "rotation speed (degree/s)")
+	flag.Float64Var(&opts.Kinematics.Acceleration, "acceleration", opts.Kinematics.Acceleration, "linear acceleration (m/s^2)")
+	flag.DurationVar(&opts.Kinematics.UpdateRate, "update-rate", opts.Kinematics.UpdateRate, "pose update interval")
+	flag.DurationVar(&opts.Timing.MotionControlDelay, "motion-delay", opts.Timing.MotionControlDelay, "posture change duration")
+	flag.DurationVar(&opts.Timing.HeadDelay, "head-delay", opts.Timing.HeadDelay, "head angle duration")
+	flag.DurationVar(&opts.Timing.DockDelay, "dock-delay", opts.Timing.DockDelay, "docking approach duration")
+	flag.DurationVar(&opts.Timing.TTSBaseDelay, "tts-base-delay", opts.Timing.TTSBaseDelay, "fixed TTS duration")
+	flag.DurationVar(&opts.Timing.TTSPerCharacter, "tts-char-delay", opts.Timing.TTSPerCharacter, "TTS duration per character")
+	scenarioPath := flag.String("scenario", "", "YAML scenario file scheduling faults against the robot")
+	mapPath := flag.String("map", "", "YAML map file with landmarks and obstacles")
+	fleetPath := flag.String("fleet", "", "YAML fleet file describing several simulated robots")
+	robotCount := flag.Int("robots", 1, "number of simulated robots when no fleet file is given")
+
+	// Settings from -config file, environment and flags
+	settingsConfig, printConfig, err := settings.Load(flag.CommandLine, os.Args[1:], settings.RobotServer)
+	if err != nil {
+		log.Fatalln("Unable to load settings:", err)
+	}
+	if printConfig {
+		settingsConfig.Print(os.Stdout)
+		return
+	}
+	// before the robots are created, each one logs through the default logger
+	logging.Setup(settingsConfig.Log, settings.RobotServer)
+	listen := settingsConfig.Simulator.Listen
+	host, _, _ := net.SplitHostPort(listen)
+
+	if opts.Kinematics.LinearSpeed <= 0 || opts.Kinematics.AngularSpeed <= 0 || opts.Kinematics.Acceleration <= 0 || opts.Kinematics.UpdateRate <= 0 {
+		fatal("Kinematic settings must be positive")
+	}
+
+	if *mapPath != "" {
+		navMap, err := simulator.LoadMap(*mapPath)
+		if err != nil {
+			fatal("Unable to load map", "error", err)
+		}
+		opts.Map = navMap
+	}
+
+	// single robot unless a fleet is requested
+	var fleet *simulator.FleetConfig
+	switch {
+	case *fleetPath != "":
+		loaded, err := simulator.LoadFleet(*fleetPath)
+		if err != nil {
+			fatal("Unable to load fleet", "error", err)
+		}
+		fleet = loaded
+	case *robotCount > 1:
+		fleet = simulator.NewFleetConfig(*robotCount)
+	case *robotCount < 1:
+		fatal("At least one robot is required")
+	}
+
+	if fleet == nil {
+		robotSim := simulator.NewMockRobotWithOptions(opts)
+		runScenario(*scenarioPath, robotSim)
+
+		robotHandler := client.NewRobotHandler(robotSim)
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/", robotHandler.HandleWS)
+		robotHandler.RegisterAdminRoutes(mux, "/admin")
+		slog.Info("Mock Robot Server started, admin API on /admin", "listen", listen)
+		if err := http.ListenAndServe(listen, mux); err != nil {
+			fatal("Unable to serve robot", "error", err)
+		}
+		return
+	}
+
+	// every robot is served under /robots/{name} and /admin/robots/{name},
+	// the first one also under / and /admin for existing clients
+	mux := http.NewServeMux()
+	names := []string{}
+	for i, robotConfig := range fleet.Robots {
+		robotOpts, err := robotConfig.Options(opts)
+		if err != nil {
+			fatal("Unable to configure robot", "error", err)
+		}
+
+		robotSim := simulator.NewMockRobotWithOptions(robotOpts)
+		if robotConfig.Scenario != "" {
+			runScenario(robotConfig.Scenario, robotSim)
+		} else {
+			runScenario(*scenarioPath, robotSim)
+		}
+
+		robotHandler := client.NewRobotHandler(robotSim)
+		mux.HandleFunc("/robots/"+robotConfig.Name, robotHandler.HandleWS)
+		robotHandler.RegisterAdminRoutes(mux, "/admin/robots/"+robotConfig.Name)
+		if i == 0 {
+			mux.HandleFunc("/", robotHandler.HandleWS)
+			robotHandler.RegisterAdminRoutes(mux, "/admin")
+		}
+		names = append(names, robotConfig.Name)
+		slog.Info("Robot available", logging.RobotID, robotConfig.Name, "url", "ws://"+listen+"/robots/"+robotConfig.Name)
+
+		if robotConfig.Port != 0 {
+			addr := net.JoinHostPort(host, strconv.Itoa(robotConfig.Port))
+			go func() {
+				slog.Info("Robot also available", logging.RobotID, robotConfig.Name, "url", "ws://"+addr+"/")
+				robotMux := http.NewServeMux()
+				robotMux.HandleFunc("/", robotHandler.HandleWS)
+				robotHandler.RegisterAdminRoutes(robotMux, "/admin")
+				if err := http.ListenAndServe(addr, robotMux); err != nil {
+					fatal("Unable to serve robot", logging.RobotID, robotConfig.Name, "error", err)
+				}
+			}()
+		}
+	}
+
+	mux.HandleFunc("GET /admin/robots", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"robots": names})
+	})
+
+	slog.Info("Mock Robot Server started", "listen", listen, "robots", len(fleet.Robots))
+	if err := http.ListenAndServe(listen, mux); err != nil {
+		fatal("Unable to serve robots", "error", err)
+	}
+}
+
+func runScenario(path string, robotSim *simulator.MockRobot) {
+
+	if path == "" {
+		return
+	}
+
+	scenario, err := simulator.LoadScenario(path)
+	if err != nil {
+		fatal("Unable to load scenario", "error", err)
+	}
+	scenario.Run(context.Background(), robotSim)
+}
+
+// fatal logs the error and exits, like log.Fatal for the slog output.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/chungweeeei/Temporal-robot-project/internal/activity"
+	"github.com/chungweeeei/Temporal-robot-project/internal/health"
+	"github.com/chungweeeei/Temporal-robot-project/internal/metrics"
+	"go.temporal.io/sdk/client"
+)
+
+// RobotConnection tracks the status subscription for the health endpoints.
+type RobotConnection struct {
+	mu        sync.Mutex
+	connected bool
+	since     time.Time
+	lastError error
+}
+
+func NewRobotConnection() *RobotConnection {
+	return &RobotConnection{since: time.Now()}
+}
+
+func (r *RobotConnection) Set(connected bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.connected != connected {
+		r.since = time.Now()
+	}
+	r.connected = connected
+	r.lastError = err
+}
+
+func (r *RobotConnection) Check(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.connected {
+		return nil
+	}
+	if r.lastError != nil {
+		return fmt.Errorf("disconnected for %s: %v", time.Since(r.since).Round(time.Second), r.lastError)
+	}
+	return fmt.Errorf("not connected for %s", time.Since(r.since).Round(time.Second))
+}
+
+// ServeHealth exposes /healthz, failing once the robot sent no status for lostTimeout so the
+// worker gets restarted, /readyz, which also needs Temporal and a fresh robot status, and /metrics.
+func ServeHealth(addr string, temporalClient client.Client, robot *RobotConnection, cache *activity.CacheStatus, lostTimeout time.Duration) {
+
+	started := time.Now()
+	statusAge := func() time.Duration {
+		if age, ok := cache.Age(); ok {
+			return age
+		}
+		return time.Since(started)
+	}
+
+	liveness := health.NewChecker()
+	liveness.Add("robot", func(ctx context.Context) error {
+		if age := statusAge(); age > lostTimeout {
+			return fmt.Errorf("no robot status for %s", age.Round(time.Second))
+		}
+		return nil
+	})
+
+	readiness := health.NewChecker()
+	readiness.Add("temporal", func(ctx context.Context) error {
+		_, err := temporalClient.CheckHealth(ctx, &client.CheckHealthRequest{})
+		return err
+	})
+	readiness.Add("rosbridge", robot.Check)
+	readiness.Add("robot_status", func(ctx context.Context) error {
+		if !cache.IsReady() {
+			return activity.ErrStatusNotAvailable
+		}
+		if age := statusAge(); age > activity.StatusStaleAfter {
+			return fmt.Errorf("%w, last update %s ago", activity.ErrStatusStale, age.Round(time.Second))
+		}
+		return nil
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /healthz", liveness)
+	mux.Handle("GET /readyz", readiness)
+	mux.Handle("GET /metrics", metrics.Handler())
+
+	slog.Info("Health endpoints started", "listen", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("Unable to serve health endpoints", "error", err)
+		os.Exit(1)
+	}
+}
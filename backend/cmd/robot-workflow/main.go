@@ -2,57 +2,127 @@ package main
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
+	"errors"
+	"flag"
 	"log"
+	"log/slog"
 	"os"
 	"time"
 
 	"github.com/chungweeeei/Temporal-robot-project/internal/activity"
-	config "github.com/chungweeeei/Temporal-robot-project/internal/config/activity"
+	"github.com/chungweeeei/Temporal-robot-project/internal/audit"
+	"github.com/chungweeeei/Temporal-robot-project/internal/config/settings"
+	"github.com/chungweeeei/Temporal-robot-project/internal/database"
+	"github.com/chungweeeei/Temporal-robot-project/internal/logging"
+	"github.com/chungweeeei/Temporal-robot-project/internal/metrics"
+	"github.com/chungweeeei/Temporal-robot-project/internal/robot/status"
+	"github.com/chungweeeei/Temporal-robot-project/internal/temporalclient"
+	"github.com/chungweeeei/Temporal-robot-project/internal/tracing"
 	"github.com/chungweeeei/Temporal-robot-project/internal/workflow"
-	"github.com/chungweeeei/Temporal-robot-project/pkg"
-	"github.com/chungweeeei/Temporal-robot-project/utils"
 	"github.com/gorilla/websocket"
 	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/interceptor"
 	"go.temporal.io/sdk/worker"
+	sdkworkflow "go.temporal.io/sdk/workflow"
 )
 
 func main() {
 
-	// Register Temporal client
-	c, err := client.Dial(client.Options{
-		HostPort: "localhost:7233",
+	// Settings from -config file, environment and flags
+	settingsConfig, printConfig, err := settings.Load(flag.CommandLine, os.Args[1:], settings.Worker)
+	if err != nil {
+		log.Fatalln("Unable to load settings:", err)
+	}
+	if printConfig {
+		settingsConfig.Print(os.Stdout)
+		return
+	}
+	workerConfig := settingsConfig.Worker
+
+	// robot_url selects one robot of a simulated fleet, e.g. ws://localhost:9090/robots/alpha
+	robotURL := workerConfig.URL()
+	robotID := workerConfig.ID()
+
+	// Every line of this worker, SDK and activity logs included, names its robot
+	logger := logging.Setup(settingsConfig.Log, settings.Worker).With(logging.RobotID, robotID)
+	slog.SetDefault(logger)
+
+	// Continue the REST request traces in workflows, activities and rosbridge calls
+	shutdownTracing, err := tracing.Setup(settingsConfig.Tracing, string(settings.Worker))
+	if err != nil {
+		logger.Error("Unable to set up tracing", "error", err)
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+	tracingInterceptor, err := tracing.TemporalInterceptor()
+	if err != nil {
+		logger.Error("Unable to create tracing interceptor", "error", err)
+		os.Exit(1)
+	}
+
+	// Register Temporal client, the worker inherits its interceptors
+	c, err := temporalclient.Dial(settingsConfig.Temporal, client.Options{
+		// the caller of each REST request is recorded in the workflow history
+		ContextPropagators: []sdkworkflow.ContextPropagator{audit.NewActorPropagator(), logging.NewPropagator()},
+		MetricsHandler:     metrics.NewTemporalHandler(),
+		Interceptors:       []interceptor.ClientInterceptor{tracingInterceptor},
+		Logger:             logging.TemporalLogger(logger),
 	})
 	if err != nil {
-		log.Fatalln("Unable to create Temporal client", err)
+		logger.Error("Unable to create Temporal client", "error", err)
+		os.Exit(1)
 	}
 	defer c.Close()
 
-	// Check robot ip settings in environment variables
-	robotIP := os.Getenv("ROBOT_IP")
-	if robotIP == "" {
-		robotIP = "localhost"
+	// Initialize database connection for execution records
+	db, err := database.InitDB(settingsConfig.Database, settingsConfig.Timezone)
+	if err != nil {
+		logger.Error("Unable to initialize database", "error", err)
+		os.Exit(1)
 	}
+	models := database.New(db)
 
 	// Register StatusCache instance
 	statusCache := &activity.CacheStatus{}
 
+	// Telemetry history of the status stream
+	telemetry := activity.NewTelemetryRecorder(models.Telemetry, robotID)
+	telemetry.Interval = time.Duration(workerConfig.TelemetryInterval) * time.Second
+	telemetry.Retention = time.Duration(workerConfig.TelemetryRetentionDays) * 24 * time.Hour
+
 	// Background go routine for robot status subscription
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	go RobotStatusSubscriber(ctx, fmt.Sprintf("ws://%s:9090/", robotIP), statusCache)
+	robotConnection := NewRobotConnection()
+	started := time.Now()
+	metrics.RegisterWorker(robotID, func() time.Duration {
+		if age, ok := statusCache.Age(); ok {
+			return age
+		}
+		return time.Since(started)
+	})
+	go telemetry.Run(ctx)
+	go RobotStatusSubscriber(ctx, robotURL, statusCache, telemetry, robotConnection)
+	go ServeHealth(workerConfig.HealthListen, c, robotConnection, statusCache, time.Duration(workerConfig.RobotLostTimeout)*time.Second)
 
 	// Register temporal worker
-	w := worker.New(c, "ROBOT_TASK_QUEUE", worker.Options{})
+	w := worker.New(c, settingsConfig.Temporal.TaskQueue, worker.Options{
+		Interceptors: []interceptor.WorkerInterceptor{&metrics.ActivityInterceptor{}, &logging.WorkerInterceptor{}},
+	})
 
-	activities := activity.NewRobotActivities(robotIP, statusCache)
+	activities := activity.NewRobotActivities(workerConfig.RobotIP, statusCache)
+	activities.Client.RobotURL = robotURL
+	activities.MinMoveBatteryLevel = workerConfig.MinMoveBatteryLevel
+	activities.StuckTimeout = time.Duration(workerConfig.MoveStuckTimeout) * time.Second
+	executionActivities := activity.NewExecutionActivities(models.Execution, robotID)
 	w.RegisterWorkflow(workflow.RobotWorkflow)
 	w.RegisterActivity(activities)
+	w.RegisterActivity(executionActivities)
 
 	err = w.Run(worker.InterruptCh())
 	if err != nil {
-		log.Fatalln("Unable to start worker", err)
+		logger.Error("Unable to start worker", "error", err)
+		os.Exit(1)
 	}
 }
 
@@ -60,47 +130,30 @@ func RobotStatusSubscriber(
 	ctx context.Context,
 	wsURL string,
 	cache *activity.CacheStatus,
+	telemetry *activity.TelemetryRecorder,
+	connection *RobotConnection,
 ) {
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		default:
-			if err := subscribeLoop(ctx, wsURL, cache); err != nil {
-				log.Println("Subscriber error, reconnecting in 5s:", err)
+			if err := subscribeLoop(ctx, wsURL, cache, telemetry, connection); err != nil {
+				connection.Set(false, err)
+				metrics.RosbridgeReconnect()
+				slog.Warn("Subscriber error, reconnecting in 5s", "error", err)
 				time.Sleep(5 * time.Second)
 			}
 		}
 	}
 }
 
-type RawRobotStatus struct {
-	ApiID        int         `json:"api_id"`
-	BatteryLevel interface{} `json:"battery_level"` // Could be string "94" or int 94
-	Pose         struct {
-		Position struct {
-			X interface{} `json:"x"`
-			Y interface{} `json:"y"`
-			Z interface{} `json:"z"`
-		} `json:"position"`
-		Orientation struct {
-			X interface{} `json:"x"`
-			Y interface{} `json:"y"`
-			Z interface{} `json:"z"`
-			W interface{} `json:"w"`
-		} `json:"orientation"`
-	} `json:"pose"`
-	MissionID interface{} `json:"mission_id"`
-	Mission   struct {
-		Code    interface{} `json:"code"`
-		Message interface{} `json:"message"`
-	} `json:"mission"`
-}
-
 func subscribeLoop(
 	ctx context.Context,
 	wsURL string,
 	cache *activity.CacheStatus,
+	telemetry *activity.TelemetryRecorder,
+	connection *RobotConnection,
 ) error {
 
 	// Regsiter another websocket session
@@ -121,8 +174,10 @@ func subscribeLoop(
 	if err := conn.WriteJSON(subscribeMsg); err != nil {
 		return err
 	}
+	connection.Set(true, nil)
 
 	// 2. Continuously read message
+	reported := map[string]bool{}
 	for {
 		select {
 		case <-ctx.Done():
@@ -133,42 +188,33 @@ func subscribeLoop(
 				return err
 			}
 
-			var msg pkg.RobotTopicResponse
-			if err := json.Unmarshal(message, &msg); err != nil {
+			robotStatus, fieldErrors, err := status.Decode(message)
+			if err != nil {
+				slog.Warn("Dropping robot status", "error", err)
 				continue
 			}
-
-			var resp struct {
-				DeviceName   string         `json:"device_name"`
-				DeviceStatus RawRobotStatus `json:"device_status"`
-				TimeStamp    string         `json:"timestamp"`
-			}
-			if err := json.Unmarshal([]byte(msg.Msg.Data), &resp); err != nil {
-				continue
+			// every problem is counted, log each field once per connection
+			for _, fieldErr := range fieldErrors {
+				if !reported[fieldErr.Field] {
+					reported[fieldErr.Field] = true
+					slog.Warn("Robot status field ignored", "field", fieldErr.Field, "error", fieldErr.Err)
+				}
 			}
 
-			if resp.DeviceStatus.BatteryLevel == nil {
-				continue
+			// an unusable battery or pose keeps the cached value, the status is skipped without one
+			var last *status.RobotStatus
+			if cached, err := cache.Get(); !errors.Is(err, activity.ErrStatusNotAvailable) {
+				last = &cached
 			}
-
-			status := activity.RobotStatus{
-				ApiID:        resp.DeviceStatus.ApiID,
-				BatteryLevel: utils.ToInt(resp.DeviceStatus.BatteryLevel),
+			robotStatus, ok := status.KeepLastGood(robotStatus, fieldErrors, last)
+			if !ok {
+				continue
 			}
-			status.Pose.Position.X = utils.ToFloat(resp.DeviceStatus.Pose.Position.X)
-			status.Pose.Position.Y = utils.ToFloat(resp.DeviceStatus.Pose.Position.Y)
-			status.Pose.Position.Z = utils.ToFloat(resp.DeviceStatus.Pose.Position.Z)
-			status.Pose.Orientation.X = utils.ToFloat(resp.DeviceStatus.Pose.Orientation.X)
-			status.Pose.Orientation.Y = utils.ToFloat(resp.DeviceStatus.Pose.Orientation.Y)
-			status.Pose.Orientation.Z = utils.ToFloat(resp.DeviceStatus.Pose.Orientation.Z)
-			status.Pose.Orientation.W = utils.ToFloat(resp.DeviceStatus.Pose.Orientation.W)
-
-			status.MissionID = resp.DeviceStatus.MissionID.(string)
-			status.Mission.Code = config.MissionCode(utils.ToInt(resp.DeviceStatus.Mission.Code))
-			status.Mission.Message = resp.DeviceStatus.Mission.Message.(string)
 
 			// Update cache value
-			cache.Update(status)
+			cache.Update(robotStatus)
+			telemetry.Record(robotStatus)
+			metrics.RobotStatus(robotStatus)
 		}
 	}
 }
@@ -1,13 +1,23 @@
 package activity
 
+import (
+	"time"
+
+	config "github.com/chungweeeei/Temporal-robot-project/internal/config/activity"
+)
+
 type RobotActivities struct {
-	Client      *RobotClient
-	CacheStatus *CacheStatus
+	Client              *RobotClient
+	CacheStatus         *CacheStatus
+	MinMoveBatteryLevel int
+	StuckTimeout        time.Duration
 }
 
 func NewRobotActivities(robotIP string, cacheStatus *CacheStatus) *RobotActivities {
 	return &RobotActivities{
-		Client:      NewRobotClient(robotIP),
-		CacheStatus: cacheStatus,
+		Client:              NewRobotClient(robotIP),
+		CacheStatus:         cacheStatus,
+		MinMoveBatteryLevel: config.DefaultMinMoveBatteryLevel,
+		StuckTimeout:        config.DefaultStuckTimeoutSeconds * time.Second,
 	}
 }
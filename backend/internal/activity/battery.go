@@ -0,0 +1,67 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chungweeeei/Temporal-robot-project/pkg"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+)
+
+// ErrTypeLowBattery is the application error type returned when the battery guard refuses a command
+const ErrTypeLowBattery = "LowBattery"
+
+// checkBatteryGuard refuses to start a motion when the cached battery level is below the configured threshold.
+// Missing or stale status is not treated as low battery, the motion itself will fail on a disconnected robot.
+func (ra *RobotActivities) checkBatteryGuard(options pkg.MoveOptions) error {
+
+	if options.SkipBatteryGuard {
+		return nil
+	}
+
+	status, err := ra.CacheStatus.Get()
+	if err != nil {
+		return nil
+	}
+
+	if status.BatteryLevel < ra.MinMoveBatteryLevel {
+		return temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("battery level %d%% is below the minimum %d%% required to move", status.BatteryLevel, ra.MinMoveBatteryLevel),
+			ErrTypeLowBattery,
+			nil,
+		)
+	}
+
+	return nil
+}
+
+// MonitorBattery returns once the battery level drops to or below criticalLevel.
+// It runs alongside the flow for the whole workflow and is cancelled when the workflow ends.
+func (ra *RobotActivities) MonitorBattery(ctx context.Context, criticalLevel int) (int, error) {
+
+	logger := activity.GetLogger(ctx)
+
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-ticker.C:
+			status, err := ra.CacheStatus.Get()
+			if err != nil {
+				activity.RecordHeartbeat(ctx, err.Error())
+				continue
+			}
+
+			if status.BatteryLevel <= criticalLevel {
+				logger.Warn("Battery level reached critical level", "battery_level", status.BatteryLevel, "critical_level", criticalLevel)
+				return status.BatteryLevel, nil
+			}
+			activity.RecordHeartbeat(ctx, status.BatteryLevel)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package activity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	config "github.com/chungweeeei/Temporal-robot-project/internal/config/activity"
+	"go.temporal.io/sdk/activity"
+)
+
+// Charge starts charging on the dock and waits until the battery reaches params["target"] percent (default 100).
+func (ra *RobotActivities) Charge(ctx context.Context, params map[string]interface{}) (string, error) {
+
+	logger := activity.GetLogger(ctx)
+
+	target := 100
+	if targetFloat, ok := params["target"].(float64); ok {
+		target = int(targetFloat)
+	}
+	if target <= 0 || target > 100 {
+		return "", fmt.Errorf("invalid parameters for Charge activity")
+	}
+
+	if status, err := ra.CacheStatus.Get(); err == nil && status.BatteryLevel >= target {
+		return fmt.Sprintf("Battery already at %d%%", status.BatteryLevel), nil
+	}
+
+	// Step 1: send charge command
+	_, err := executeWithHeartbeat(ctx, func() (string, error) {
+		data := map[string]int{
+			"api_id": config.RobotChargeControlID,
+			"action": config.ChargeActionID,
+		}
+
+		dataBytes, err := json.Marshal(data)
+		if err != nil {
+			return "", err
+		}
+
+		logger.Info("Call Charge Service", "target", target)
+		response, err := ra.Client.CallService(ctx, "Charge", string(dataBytes))
+		if err != nil {
+			return "", err
+		}
+
+		return response, checkResponseStatus(response)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Step 2: Polling until battery reaches target
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Charge activity cancelled")
+			return "", ctx.Err()
+
+		case <-ticker.C:
+			status, err := ra.CacheStatus.Get()
+			if err != nil {
+				logger.Error("Failed to get robot status during charge", "error", err)
+				activity.RecordHeartbeat(ctx, err.Error())
+				continue
+			}
+
+			if !status.Docked {
+				return "", fmt.Errorf("robot left the dock while charging at %d%%", status.BatteryLevel)
+			}
+
+			if status.BatteryLevel >= target {
+				return fmt.Sprintf("Battery charged to %d%%", status.BatteryLevel), nil
+			}
+			activity.RecordHeartbeat(ctx, fmt.Sprintf("Charging at %d%%", status.BatteryLevel))
+		}
+	}
+}
@@ -2,11 +2,18 @@ package activity
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	transport "github.com/chungweeeei/Temporal-robot-project/internal/activity/transport/websocket"
+	"github.com/chungweeeei/Temporal-robot-project/internal/metrics"
+	"github.com/chungweeeei/Temporal-robot-project/internal/tracing"
 	"github.com/chungweeeei/Temporal-robot-project/pkg"
 	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type RobotClient struct {
@@ -23,7 +30,33 @@ func NewRobotClient(robotIP string) *RobotClient {
 	}
 }
 
-func (r *RobotClient) CallService(ctx context.Context, actionType pkg.ActivityType, data interface{}) (string, error) {
+// CallService sends one rosbridge call_service request and waits for its response. The call is
+// a client span under the activity's span, carrying the service, API ID, response code and frame
+// sizes. The frames themselves only with tracing.RecordFrames, they hold TTS text and coordinates.
+func (r *RobotClient) CallService(ctx context.Context, actionType pkg.ActivityType, data interface{}) (result string, err error) {
+
+	req := serviceRequest(string(actionType), data)
+
+	ctx, span := tracing.Tracer().Start(ctx, "rosbridge "+req.Op+" "+req.Service,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("rosbridge.op", req.Op),
+			attribute.String("rosbridge.service", req.Service),
+			attribute.String("rosbridge.type", req.Type),
+			attribute.String("robot.activity_type", string(actionType)),
+			attribute.String("robot.url", r.RobotURL),
+		),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+	if apiID, ok := requestAPIID(data); ok {
+		span.SetAttributes(attribute.Int("robot.api_id", apiID))
+	}
 
 	// Register Websocket Dialer Connection
 	conn, _, err := r.Dialer.DialContext(ctx, r.RobotURL, nil)
@@ -35,15 +68,17 @@ func (r *RobotClient) CallService(ctx context.Context, actionType pkg.ActivityTy
 	doneCh := make(chan struct{})
 	defer close(doneCh)
 
-	payload, err := generatePayload(string(actionType), data)
+	payload, err := json.Marshal(req)
 	if err != nil {
 		return "", fmt.Errorf("Failed to generate payload: %v", err)
 	}
 
+	sent := time.Now()
 	err = conn.WriteMessage(websocket.TextMessage, payload)
 	if err != nil {
 		return "", fmt.Errorf("Failed to write message via websocket: %v", err)
 	}
+	span.AddEvent("websocket.send", trace.WithAttributes(frameAttributes(payload)...))
 
 	type readResult struct {
 		data []byte
@@ -63,8 +98,30 @@ func (r *RobotClient) CallService(ctx context.Context, actionType pkg.ActivityTy
 		if res.err != nil {
 			return "", fmt.Errorf("Failed to read message via websocket: %v", res.err)
 		}
-		return parseResponse(res.data)
+		metrics.RosbridgeRoundTrip(actionType, time.Since(sent))
+		span.AddEvent("websocket.receive", trace.WithAttributes(frameAttributes(res.data)...))
+
+		response, err := parseResponse(res.data)
+		if err != nil {
+			return "", err
+		}
+		var system systemResponse
+		if req.Service == "/api/system" && json.Unmarshal([]byte(response), &system) == nil {
+			span.SetAttributes(attribute.Int("robot.response_code", system.Status.Code))
+			if system.Status.Code != 0 {
+				span.SetStatus(codes.Error, system.Status.Message)
+			}
+		}
+		return response, nil
 	case <-ctx.Done():
 		return "", ctx.Err()
 	}
 }
+
+func frameAttributes(frame []byte) []attribute.KeyValue {
+	attributes := []attribute.KeyValue{attribute.Int("websocket.frame_size", len(frame))}
+	if tracing.RecordFrames() {
+		attributes = append(attributes, attribute.String("websocket.frame", string(frame)))
+	}
+	return attributes
+}
@@ -0,0 +1,34 @@
+package activity
+
+import (
+	"context"
+	"encoding/json"
+
+	config "github.com/chungweeeei/Temporal-robot-project/internal/config/activity"
+	"go.temporal.io/sdk/activity"
+)
+
+func (ra *RobotActivities) Dock(ctx context.Context, params map[string]interface{}) (string, error) {
+
+	logger := activity.GetLogger(ctx)
+
+	return executeWithHeartbeat(ctx, func() (string, error) {
+		data := map[string]int{
+			"api_id": config.RobotChargeControlID,
+			"action": config.DockActionID,
+		}
+
+		dataBytes, err := json.Marshal(data)
+		if err != nil {
+			return "", err
+		}
+
+		logger.Info("Call Dock Service")
+		response, err := ra.Client.CallService(ctx, "Dock", string(dataBytes))
+		if err != nil {
+			return "", err
+		}
+
+		return response, checkResponseStatus(response)
+	})
+}
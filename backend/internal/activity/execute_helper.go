@@ -3,6 +3,7 @@ package activity
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/chungweeeei/Temporal-robot-project/pkg"
@@ -13,11 +14,11 @@ const (
 	ACTIVITY_HEARTBEAT_INTERVAL = 3
 )
 
-func generatePayload(actionType string, data any) ([]byte, error) {
+func serviceRequest(actionType string, data any) pkg.RobotServiceRequest {
 
 	var req pkg.RobotServiceRequest
 	switch actionType {
-	case "Standup", "Sitdown", "Move", "TTS", "Status", "Stop":
+	case "Standup", "Standdown", "Sitdown", "Move", "TTS", "Status", "Stop", "Dock", "Charge":
 		req.Op = "call_service"
 		req.Service = "/api/system"
 		req.Type = "custom_msgs/srv/Api"
@@ -37,12 +38,7 @@ func generatePayload(actionType string, data any) ([]byte, error) {
 		}
 	}
 
-	payload, err := json.Marshal(req)
-	if err != nil {
-		return nil, err
-	}
-
-	return payload, nil
+	return req
 }
 
 func parseResponse(msg []byte) (string, error) {
@@ -53,6 +49,42 @@ func parseResponse(msg []byte) (string, error) {
 	return resp.Values.Data, nil
 }
 
+// systemResponse is the data of an /api/system response.
+type systemResponse struct {
+	ApiID  int `json:"api_id"`
+	Status struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+}
+
+// requestAPIID is the api_id of /api/system request data, which the activities send as a JSON string.
+func requestAPIID(data any) (int, bool) {
+	encoded, ok := data.(string)
+	if !ok {
+		return 0, false
+	}
+	var req struct {
+		ApiID *int `json:"api_id"`
+	}
+	if err := json.Unmarshal([]byte(encoded), &req); err != nil || req.ApiID == nil {
+		return 0, false
+	}
+	return *req.ApiID, true
+}
+
+// checkResponseStatus returns an error when the robot rejected an /api/system call.
+func checkResponseStatus(data string) error {
+	var resp systemResponse
+	if err := json.Unmarshal([]byte(data), &resp); err != nil {
+		return fmt.Errorf("invalid response from robot: %v", err)
+	}
+	if resp.Status.Code != 0 {
+		return fmt.Errorf("robot rejected api %d (code %d): %s", resp.ApiID, resp.Status.Code, resp.Status.Message)
+	}
+	return nil
+}
+
 // 整合這種自定義 schema，使用 Golang 的 Generics (泛型) 是最完美的解決方案。
 // [Any] 表示這個函式接受任何型別 T
 func executeWithHeartbeat[T any](ctx context.Context, operation func() (T, error)) (T, error) {
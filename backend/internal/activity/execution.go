@@ -0,0 +1,60 @@
+package activity
+
+import (
+	"context"
+
+	"github.com/chungweeeei/Temporal-robot-project/internal/metrics"
+	"github.com/chungweeeei/Temporal-robot-project/internal/repository/models"
+	"github.com/chungweeeei/Temporal-robot-project/pkg"
+)
+
+// ExecutionActivities persist run and node outcomes so they outlive Temporal's retention.
+type ExecutionActivities struct {
+	Model   models.ExecutionInterface
+	RobotID string
+}
+
+func NewExecutionActivities(model models.ExecutionInterface, robotID string) *ExecutionActivities {
+	return &ExecutionActivities{
+		Model:   model,
+		RobotID: robotID,
+	}
+}
+
+func (ea *ExecutionActivities) RecordExecutionStart(ctx context.Context, event pkg.ExecutionEvent) error {
+	err := ea.Model.Start(models.Execution{
+		RunID:      event.RunID,
+		WorkflowID: event.WorkflowID,
+		ScheduleID: event.ScheduleID,
+		RobotID:    ea.RobotID,
+		Status:     string(event.Status),
+		StartTime:  event.Time,
+	})
+	if err == nil {
+		metrics.WorkflowRunStarted(event.ScheduleID)
+	}
+	return err
+}
+
+func (ea *ExecutionActivities) RecordExecutionEnd(ctx context.Context, event pkg.ExecutionEvent) error {
+	err := ea.Model.Finish(event.RunID, string(event.Status), event.Error, event.Time)
+	if err == nil {
+		metrics.WorkflowRunCompleted(event.Status)
+	}
+	return err
+}
+
+func (ea *ExecutionActivities) RecordNodeExecution(ctx context.Context, event pkg.NodeExecutionEvent) error {
+	endTime := event.EndTime
+	return ea.Model.InsertNode(models.NodeExecution{
+		RunID:        event.RunID,
+		NodeID:       event.NodeID,
+		ActivityType: string(event.Type),
+		Status:       string(event.Status),
+		Result:       event.Result,
+		Error:        event.Error,
+		StartTime:    event.StartTime,
+		EndTime:      &endTime,
+		DurationMs:   event.EndTime.Sub(event.StartTime).Milliseconds(),
+	})
+}
@@ -8,28 +8,32 @@ import (
 	"time"
 
 	config "github.com/chungweeeei/Temporal-robot-project/internal/config/activity"
-	"github.com/fatih/color"
+	"github.com/chungweeeei/Temporal-robot-project/pkg"
 	"github.com/google/uuid"
 	"go.temporal.io/sdk/activity"
 )
 
-func (ra *RobotActivities) sendStopCommand() {
+// sendStopCommand stops the given mission only, leaving any newer mission running.
+// ctx only supplies the logger, the stop is sent even when it is cancelled.
+func (ra *RobotActivities) sendStopCommand(ctx context.Context, missionID string) {
 
-	stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
 	defer cancel()
 
 	stopData := map[string]interface{}{
-		"api_id": 5000,
+		"api_id":     5000,
+		"mission_id": missionID,
 	}
 	stopBytes, _ := json.Marshal(stopData)
 
 	_, err := ra.Client.CallService(stopCtx, "Stop", string(stopBytes))
 	if err != nil {
-		color.Red("Failed to send stop command", "error", err)
+		activity.GetLogger(ctx).Error("Failed to send stop command", "mission_id", missionID, "error", err)
 	}
 }
 
-func (ra *RobotActivities) Move(ctx context.Context, params map[string]interface{}) (string, error) {
+// Move drives to the x, y and orientation of params. options are set by the workflow, never by node params.
+func (ra *RobotActivities) Move(ctx context.Context, params map[string]interface{}, options pkg.MoveOptions) (string, error) {
 
 	logger := activity.GetLogger(ctx)
 
@@ -41,6 +45,16 @@ func (ra *RobotActivities) Move(ctx context.Context, params map[string]interface
 		return "", fmt.Errorf("invalid parameters for Move activity")
 	}
 
+	if err := ra.checkBatteryGuard(options); err != nil {
+		logger.Warn("Move refused by battery guard", "error", err)
+		return "", err
+	}
+
+	if err := ra.checkPostureGuard(); err != nil {
+		logger.Warn("Move refused by posture guard", "error", err)
+		return "", err
+	}
+
 	// Step 1: send move command
 	newMissionID := uuid.New().String()
 	_, err := executeWithHeartbeat(ctx, func() (string, error) {
@@ -60,6 +74,10 @@ func (ra *RobotActivities) Move(ctx context.Context, params map[string]interface
 			logger.Error("Failed to send move command", "error", err)
 			return "", err
 		}
+		if err := checkResponseStatus(response); err != nil {
+			logger.Error("Move command rejected by robot", "error", err)
+			return "", err
+		}
 		logger.Info("Move command accepted by robot", "response", response)
 		return response, nil
 	})
@@ -71,34 +89,54 @@ func (ra *RobotActivities) Move(ctx context.Context, params map[string]interface
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
+	// stuck detection: last position the robot made progress at
+	var lastX, lastY float64
+	var lastProgress time.Time
+	started := false
+
 	for {
 		select {
 		case <-ctx.Done():
 			logger.Info("Move activity cancelled, stopping robot.")
-			ra.sendStopCommand()
+			ra.sendStopCommand(ctx, newMissionID)
 			return "", ctx.Err()
 
 		case <-ticker.C:
-			status, _ := ra.CacheStatus.Get()
-
+			status, err := ra.CacheStatus.Get()
 			if err != nil {
-				// instantly check context error
-				if ctx.Err() != nil {
-					logger.Info("Move activity cancelled (from GetStatus error).")
-					ra.sendStopCommand()
-					return "", ctx.Err()
-				}
-				logger.Error("Failed to get robot status during move", "error", err)
+				// a stale or missing status says nothing about the mission, wait for a fresh one
+				// and restart the stuck timer once it arrives
+				logger.Warn("No current robot status during move", "error", err)
+				lastProgress = time.Time{}
+				activity.RecordHeartbeat(ctx, err.Error())
 				continue
 			}
 
 			if status.MissionID != newMissionID {
+				// another goal replaced ours on the robot
+				if started {
+					return "", fmt.Errorf("move mission %s preempted by mission %s", newMissionID, status.MissionID)
+				}
 				logger.Info("Waiting for robot to start the move mission", "expected_mission_id", newMissionID, "current_mission_id", status.MissionID)
 				continue
 			}
+			started = true
 
-			if status.Mission.Code == config.MissionSuccess {
+			if lastProgress.IsZero() || math.Hypot(status.Pose.Position.X-lastX, status.Pose.Position.Y-lastY) >= config.StuckDistance {
+				lastX, lastY = status.Pose.Position.X, status.Pose.Position.Y
+				lastProgress = time.Now()
+			}
+
+			switch status.Mission.Code {
+			case config.MissionSuccess:
 				return fmt.Sprintf("Robot has reached the target location (%.2f, %.2f)", status.Pose.Position.X, status.Pose.Position.Y), nil
+			case config.MissionFailed, config.MissionAbort:
+				return "", fmt.Errorf("move mission %s ended with %s at (%.2f, %.2f)", newMissionID, status.Mission.Message, status.Pose.Position.X, status.Pose.Position.Y)
+			}
+			if ra.StuckTimeout > 0 && time.Since(lastProgress) > ra.StuckTimeout {
+				logger.Error("Robot is stuck, stopping move mission", "mission_id", newMissionID)
+				ra.sendStopCommand(ctx, newMissionID)
+				return "", fmt.Errorf("robot stuck at (%.2f, %.2f) for %s", status.Pose.Position.X, status.Pose.Position.Y, ra.StuckTimeout)
 			}
 			activity.RecordHeartbeat(ctx, fmt.Sprintf("Robot currently at (%f, %f)", status.Pose.Position.X, status.Pose.Position.Y))
 		}
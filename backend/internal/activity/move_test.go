@@ -0,0 +1,104 @@
+package activity
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	transport "github.com/chungweeeei/Temporal-robot-project/internal/activity/transport/websocket"
+	config "github.com/chungweeeei/Temporal-robot-project/internal/config/activity"
+	"github.com/chungweeeei/Temporal-robot-project/pkg"
+	"go.temporal.io/sdk/testsuite"
+)
+
+var missionIDPattern = regexp.MustCompile(`mission_id\\?":\\?"([0-9a-f-]{36})`)
+
+// fakeRobot accepts every call_service request and reports the mission ID of a move.
+type fakeRobot struct {
+	missionIDs chan string
+}
+
+func (r *fakeRobot) DialContext(ctx context.Context, urlStr string, requestHeader http.Header) (transport.WSConnection, *http.Response, error) {
+	return &fakeConnection{robot: r, responses: make(chan []byte, 1)}, nil, nil
+}
+
+type fakeConnection struct {
+	robot     *fakeRobot
+	responses chan []byte
+}
+
+func (c *fakeConnection) WriteMessage(messageType int, data []byte) error {
+	if match := missionIDPattern.FindSubmatch(data); match != nil {
+		c.robot.missionIDs <- string(match[1])
+	}
+	c.responses <- []byte(`{"op":"service_response","service":"/api/system","values":{"data":"{\"api_id\":1005,\"status\":{\"code\":0,\"message\":\"OK\"}}"}}`)
+	return nil
+}
+
+func (c *fakeConnection) ReadMessage() (int, []byte, error) {
+	response, ok := <-c.responses
+	if !ok {
+		return 0, nil, errors.New("connection closed")
+	}
+	return 1, response, nil
+}
+
+func (c *fakeConnection) Close() error {
+	return nil
+}
+
+func missionStatus(missionID string, code config.MissionCode) RobotStatus {
+	var status RobotStatus
+	status.BatteryLevel = 80
+	status.MissionID = missionID
+	status.Mission.Code = code
+	return status
+}
+
+// A stale status during a move says nothing about the robot: it must neither count towards
+// stuck detection nor be checked for the mission.
+func TestMoveWaitsOutStaleStatus(t *testing.T) {
+
+	robot := &fakeRobot{missionIDs: make(chan string, 1)}
+	cache := NewCacheStatus()
+	cache.Update(missionStatus("", config.MissionInit))
+
+	activities := &RobotActivities{
+		Client:       &RobotClient{RobotURL: "ws://robot", Dialer: robot},
+		CacheStatus:  cache,
+		StuckTimeout: 1500 * time.Millisecond,
+	}
+
+	go func() {
+		missionID := <-robot.missionIDs
+		cache.Update(missionStatus(missionID, config.MissionStart))
+
+		// the status link drops right away: the cached status turns stale for longer than the stuck timeout
+		cache.mu.Lock()
+		cache.lastUpdated = time.Now().Add(-time.Minute)
+		cache.mu.Unlock()
+		time.Sleep(4 * time.Second)
+
+		cache.Update(missionStatus(missionID, config.MissionSuccess))
+	}()
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestActivityEnvironment()
+	env.RegisterActivity(activities)
+
+	params := map[string]interface{}{"x": 1.0, "y": 2.0, "orientation": 90.0}
+	result, err := env.ExecuteActivity(activities.Move, params, pkg.MoveOptions{})
+	if err != nil {
+		t.Fatalf("move failed: %v", err)
+	}
+	var message string
+	if err := result.Get(&message); err != nil {
+		t.Fatal(err)
+	}
+	if message == "" {
+		t.Error("empty move result")
+	}
+}
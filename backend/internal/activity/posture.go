@@ -0,0 +1,59 @@
+package activity
+
+import (
+	"context"
+	"encoding/json"
+
+	config "github.com/chungweeeei/Temporal-robot-project/internal/config/activity"
+	"github.com/chungweeeei/Temporal-robot-project/pkg"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+)
+
+// ErrTypeInvalidPosture is the application error type returned when a command is not allowed in the current posture
+const ErrTypeInvalidPosture = "InvalidPosture"
+
+// callMotionControl sends a posture change and fails when the robot rejects it.
+func (ra *RobotActivities) callMotionControl(ctx context.Context, activityType pkg.ActivityType, actionID int) (string, error) {
+
+	logger := activity.GetLogger(ctx)
+
+	return executeWithHeartbeat(ctx, func() (string, error) {
+		data := map[string]int{
+			"api_id": config.RobotMotionControlID,
+			"action": actionID,
+		}
+
+		dataBytes, err := json.Marshal(data)
+		if err != nil {
+			return "", err
+		}
+
+		logger.Info("Call " + string(activityType) + " Service")
+		response, err := ra.Client.CallService(ctx, activityType, string(dataBytes))
+		if err != nil {
+			return "", err
+		}
+
+		return response, checkResponseStatus(response)
+	})
+}
+
+// checkPostureGuard refuses to move while the robot reports it is sitting.
+func (ra *RobotActivities) checkPostureGuard() error {
+
+	status, err := ra.CacheStatus.Get()
+	if err != nil {
+		return nil
+	}
+
+	if status.Posture == config.PostureSitDown {
+		return temporal.NewNonRetryableApplicationError(
+			"robot is sitting, stand up before moving",
+			ErrTypeInvalidPosture,
+			nil,
+		)
+	}
+
+	return nil
+}
@@ -2,31 +2,11 @@ package activity
 
 import (
 	"context"
-	"encoding/json"
 
 	config "github.com/chungweeeei/Temporal-robot-project/internal/config/activity"
-	"go.temporal.io/sdk/activity"
+	"github.com/chungweeeei/Temporal-robot-project/pkg"
 )
 
 func (ra *RobotActivities) Sitdown(ctx context.Context, params map[string]interface{}) (string, error) {
-
-	logger := activity.GetLogger(ctx)
-
-	return executeWithHeartbeat(ctx, func() (string, error) {
-
-		data := map[string]int{
-			"api_id": config.RobotMotionControlID,
-			"action": config.SitDownActionID,
-		}
-
-		dataBytes, err := json.Marshal(data)
-		if err != nil {
-			return "", err
-		}
-
-		logger.Info("Call Sitdown Service")
-
-		return ra.Client.CallService(ctx, "Sitdown", string(dataBytes))
-	})
-
+	return ra.callMotionControl(ctx, pkg.ActivitySitDown, config.SitDownActionID)
 }
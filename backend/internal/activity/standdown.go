@@ -0,0 +1,12 @@
+package activity
+
+import (
+	"context"
+
+	config "github.com/chungweeeei/Temporal-robot-project/internal/config/activity"
+	"github.com/chungweeeei/Temporal-robot-project/pkg"
+)
+
+func (ra *RobotActivities) Standdown(ctx context.Context, params map[string]interface{}) (string, error) {
+	return ra.callMotionControl(ctx, pkg.ActivityStandDown, config.StandDownActionID)
+}
@@ -2,28 +2,11 @@ package activity
 
 import (
 	"context"
-	"encoding/json"
 
 	config "github.com/chungweeeei/Temporal-robot-project/internal/config/activity"
-	"go.temporal.io/sdk/activity"
+	"github.com/chungweeeei/Temporal-robot-project/pkg"
 )
 
 func (ra *RobotActivities) Standup(ctx context.Context, params map[string]interface{}) (string, error) {
-
-	logger := activity.GetLogger(ctx)
-
-	return executeWithHeartbeat(ctx, func() (string, error) {
-		data := map[string]int{
-			"api_id": config.RobotMotionControlID,
-			"action": config.StandUpActionID,
-		}
-
-		dataBytes, err := json.Marshal(data)
-		if err != nil {
-			return "", err
-		}
-
-		logger.Info("Call Standup Service")
-		return ra.Client.CallService(ctx, "Standup", string(dataBytes))
-	})
+	return ra.callMotionControl(ctx, pkg.ActivityStandUp, config.StandUpActionID)
 }
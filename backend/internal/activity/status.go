@@ -5,7 +5,7 @@ import (
 	"sync"
 	"time"
 
-	config "github.com/chungweeeei/Temporal-robot-project/internal/config/activity"
+	"github.com/chungweeeei/Temporal-robot-project/internal/robot/status"
 )
 
 var (
@@ -13,51 +13,11 @@ var (
 	ErrStatusStale        = errors.New("robot status is stale")
 )
 
-type RawRobotStatus struct {
-	ApiID        int         `json:"api_id"`
-	BatteryLevel interface{} `json:"battery_level"` // Could be string "94" or int 94
-	Pose         struct {
-		Position struct {
-			X interface{} `json:"x"`
-			Y interface{} `json:"y"`
-			Z interface{} `json:"z"`
-		} `json:"position"`
-		Orientation struct {
-			X interface{} `json:"x"`
-			Y interface{} `json:"y"`
-			Z interface{} `json:"z"`
-			W interface{} `json:"w"`
-		} `json:"orientation"`
-	} `json:"pose"`
-	MissionID interface{} `json:"mission_id"`
-	Mission   struct {
-		Code    interface{} `json:"code"`
-		Message interface{} `json:"message"`
-	} `json:"mission"`
-}
+// StatusStaleAfter is the age after which the cached status no longer describes the robot.
+const StatusStaleAfter = 10 * time.Second
 
-type RobotStatus struct {
-	ApiID        int `json:"api_id"`
-	BatteryLevel int `json:"battery_level"`
-	Pose         struct {
-		Orientation struct {
-			W float64 `json:"w"`
-			X float64 `json:"x"`
-			Y float64 `json:"y"`
-			Z float64 `json:"z"`
-		} `json:"orientation"`
-		Position struct {
-			X float64 `json:"x"`
-			Y float64 `json:"y"`
-			Z float64 `json:"z"`
-		} `json:"position"`
-	} `json:"pose"`
-	MissionID string `json:"mission_id"`
-	Mission   struct {
-		Code    config.MissionCode `json:"code"`
-		Message string             `json:"message"`
-	} `json:"mission"`
-}
+// RobotStatus is the decoded /api/info status the activities work with.
+type RobotStatus = status.RobotStatus
 
 // Status Cache (Background goroutine updates this periodically)
 type CacheStatus struct {
@@ -79,7 +39,7 @@ func (c *CacheStatus) Get() (RobotStatus, error) {
 		return RobotStatus{}, ErrStatusNotAvailable
 	}
 
-	if time.Since(c.lastUpdated) > 10*time.Second {
+	if time.Since(c.lastUpdated) > StatusStaleAfter {
 		return c.status, ErrStatusStale
 	}
 
@@ -100,3 +60,13 @@ func (c *CacheStatus) IsReady() bool {
 	defer c.mu.RUnlock()
 	return c.initialized
 }
+
+// Age is the time since the last status update, false before the first one.
+func (c *CacheStatus) Age() (time.Duration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if !c.initialized {
+		return 0, false
+	}
+	return time.Since(c.lastUpdated), true
+}
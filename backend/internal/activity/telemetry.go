@@ -0,0 +1,108 @@
+package activity
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"time"
+
+	config "github.com/chungweeeei/Temporal-robot-project/internal/config/activity"
+	"github.com/chungweeeei/Temporal-robot-project/internal/repository/models"
+)
+
+// TelemetryRecorder downsamples the status stream into the telemetry table and enforces its retention.
+// Writes happen in the background, a slow database drops samples instead of stalling the stream.
+type TelemetryRecorder struct {
+	Model     models.TelemetryInterface
+	RobotID   string
+	Interval  time.Duration
+	Retention time.Duration
+
+	samples chan models.TelemetrySample
+	last    *models.TelemetrySample
+}
+
+func NewTelemetryRecorder(model models.TelemetryInterface, robotID string) *TelemetryRecorder {
+	return &TelemetryRecorder{
+		Model:     model,
+		RobotID:   robotID,
+		Interval:  config.DefaultTelemetryIntervalSeconds * time.Second,
+		Retention: config.DefaultTelemetryRetentionDays * 24 * time.Hour,
+		samples:   make(chan models.TelemetrySample, 100),
+	}
+}
+
+// Record keeps a sample when the interval elapsed, the robot moved or its battery or mission changed.
+// It is called from the status subscriber only.
+func (t *TelemetryRecorder) Record(status RobotStatus) {
+
+	sample := models.TelemetrySample{
+		RobotID:      t.RobotID,
+		Time:         time.Now(),
+		X:            status.Pose.Position.X,
+		Y:            status.Pose.Position.Y,
+		Orientation:  yawDegree(status),
+		BatteryLevel: status.BatteryLevel,
+		Charging:     status.Charging,
+		Docked:       status.Docked,
+		MissionID:    status.MissionID,
+		MissionCode:  int(status.Mission.Code),
+	}
+
+	if last := t.last; last != nil &&
+		sample.Time.Sub(last.Time) < t.Interval &&
+		math.Hypot(sample.X-last.X, sample.Y-last.Y) < config.TelemetryMinDistance &&
+		sample.BatteryLevel == last.BatteryLevel &&
+		sample.MissionID == last.MissionID &&
+		sample.MissionCode == last.MissionCode {
+		return
+	}
+	t.last = &sample
+
+	select {
+	case t.samples <- sample:
+	default:
+		slog.Warn("Telemetry queue full, dropping sample")
+	}
+}
+
+// Run writes recorded samples and deletes expired ones until ctx is done.
+func (t *TelemetryRecorder) Run(ctx context.Context) {
+
+	retention := time.NewTicker(time.Hour)
+	defer retention.Stop()
+	t.deleteExpired()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sample := <-t.samples:
+			if err := t.Model.Insert(sample); err != nil {
+				slog.Error("Unable to store telemetry", "error", err)
+			}
+		case <-retention.C:
+			t.deleteExpired()
+		}
+	}
+}
+
+func (t *TelemetryRecorder) deleteExpired() {
+	if t.Retention <= 0 {
+		return
+	}
+	deleted, err := t.Model.DeleteBefore(time.Now().Add(-t.Retention))
+	if err != nil {
+		slog.Error("Unable to delete expired telemetry", "error", err)
+		return
+	}
+	if deleted > 0 {
+		slog.Info("Deleted expired telemetry samples", "count", deleted)
+	}
+}
+
+// yawDegree converts the status quaternion to a heading in degree.
+func yawDegree(status RobotStatus) float64 {
+	q := status.Pose.Orientation
+	return math.Atan2(2*(q.W*q.Z+q.X*q.Y), 1-2*(q.Y*q.Y+q.Z*q.Z)) * (180.0 / math.Pi)
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chungweeeei/Temporal-robot-project/internal/auth"
+	"github.com/chungweeeei/Temporal-robot-project/internal/repository/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	auditSuccess = "success"
+	auditFailure = "failure"
+
+	// maxAuditBodySize caps the request body read for its hash, workflows stay far below it
+	maxAuditBodySize = 1 << 20
+)
+
+// auditMiddleware records every mutating request of the group once its handler finished.
+// It runs after auth.Middleware, reads are not recorded.
+func auditMiddleware(events models.AuditInterface, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		bodyHash := ""
+		tooLarge := false
+		if c.Request.Body != nil {
+			body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxAuditBodySize))
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				tooLarge = true
+			} else if err != nil {
+				logger.ErrorContext(c.Request.Context(), "Unable to read request body for audit", "error", err)
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			if len(body) > 0 {
+				sum := sha256.Sum256(body)
+				bodyHash = hex.EncodeToString(sum[:])
+			}
+		}
+
+		event := models.AuditEvent{
+			Time:     time.Now(),
+			Actor:    "anonymous",
+			Action:   actionName(c.HandlerName()),
+			Method:   c.Request.Method,
+			Path:     c.Request.URL.Path,
+			Resource: c.Param("id"),
+			IP:       c.ClientIP(),
+			BodyHash: bodyHash,
+		}
+		if principal, ok := auth.PrincipalFrom(c); ok {
+			event.Actor = principal.Subject
+			event.AuthMethod = principal.Method
+		}
+
+		if tooLarge {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge,
+				gin.H{"message": fmt.Sprintf("Request body exceeds %d bytes", maxAuditBodySize)})
+		} else {
+			c.Next()
+		}
+
+		event.Status = c.Writer.Status()
+		event.Result = auditSuccess
+		if event.Status >= http.StatusBadRequest {
+			event.Result = auditFailure
+		}
+
+		if err := events.Insert(event); err != nil {
+			logger.ErrorContext(c.Request.Context(), "Unable to record audit event", "error", err)
+		}
+	}
+}
+
+// actionName turns ".../handlers.(*Handler).PauseWorkflow-fm" into "PauseWorkflow".
+func actionName(handlerName string) string {
+	name := handlerName[strings.LastIndex(handlerName, ".")+1:]
+	return strings.TrimSuffix(name, "-fm")
+}
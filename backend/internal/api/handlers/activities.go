@@ -10,7 +10,7 @@ func (h *Handler) GetActivities(c *gin.Context) {
 
 	activities, err := h.App.Model.Activity.Get()
 	if err != nil {
-		h.App.ErrorLog.Println("Unable to get activities:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to get activities", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to get activities"})
 		return
 	}
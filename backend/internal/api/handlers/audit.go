@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/chungweeeei/Temporal-robot-project/internal/audit"
+	"github.com/chungweeeei/Temporal-robot-project/internal/auth"
+	"github.com/chungweeeei/Temporal-robot-project/internal/repository/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// actorContext carries the authenticated caller into Temporal requests as the actor header,
+// and the request's trace, without the cancellation of the HTTP request.
+func (h *Handler) actorContext(c *gin.Context) context.Context {
+	return audit.WithActor(context.WithoutCancel(c.Request.Context()), actorName(c))
+}
+
+func actorName(c *gin.Context) string {
+	if principal, ok := auth.PrincipalFrom(c); ok {
+		return principal.Subject
+	}
+	return "anonymous"
+}
+
+// GetAuditEvents returns recorded control actions, newest first. Filters: actor, action, resource,
+// from and to (RFC 3339) and limit.
+func (h *Handler) GetAuditEvents(c *gin.Context) {
+
+	filter := models.AuditFilter{
+		Actor:    c.Query("actor"),
+		Action:   c.Query("action"),
+		Resource: c.Query("resource"),
+	}
+
+	for name, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if raw := c.Query(name); raw != "" {
+			parsed, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid " + name + ", expected RFC 3339 time"})
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultAuditLimit)))
+	if err != nil || limit <= 0 || limit > maxAuditLimit {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid limit"})
+		return
+	}
+	filter.Limit = limit
+
+	events, err := h.App.Model.Audit.Get(filter)
+	if err != nil {
+		h.App.Logger.ErrorContext(c.Request.Context(), "Error retrieving audit events", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to retrieve audit events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/chungweeeei/Temporal-robot-project/internal/auth"
+	"github.com/chungweeeei/Temporal-robot-project/internal/repository/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" binding:"required"`
+	Roles         []string `json:"roles" binding:"required"`
+	ExpiresInDays int      `json:"expires_in_days"`
+}
+
+type CreateAPIKeyResponse struct {
+	models.APIKey
+	Key string `json:"key"`
+}
+
+// ErrUnknownRole is returned by NewAPIKey for a role that is not one of auth.Roles.
+var ErrUnknownRole = errors.New("unknown role")
+
+// NewAPIKey generates and stores a key, the plain key is only returned here.
+func NewAPIKey(keys models.APIKeyInterface, name string, roles []string, expiresIn time.Duration) (*CreateAPIKeyResponse, error) {
+
+	for _, role := range roles {
+		if !auth.ValidRole(role) {
+			return nil, fmt.Errorf("%w %q", ErrUnknownRole, role)
+		}
+	}
+
+	plain, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key := models.APIKey{
+		ID:        uuid.New().String(),
+		Name:      name,
+		Prefix:    prefix,
+		Hash:      hash,
+		Roles:     roles,
+		CreatedAt: time.Now(),
+	}
+	if expiresIn > 0 {
+		expiresAt := key.CreatedAt.Add(expiresIn)
+		key.ExpiresAt = &expiresAt
+	}
+
+	if err := keys.Create(key); err != nil {
+		return nil, err
+	}
+
+	return &CreateAPIKeyResponse{APIKey: key, Key: plain}, nil
+}
+
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request payload"})
+		return
+	}
+	if len(req.Roles) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "At least one role is required"})
+		return
+	}
+	if req.ExpiresInDays < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "expires_in_days must not be negative"})
+		return
+	}
+
+	resp, err := NewAPIKey(h.App.Model.APIKey, req.Name, req.Roles, time.Duration(req.ExpiresInDays)*24*time.Hour)
+	if errors.Is(err, ErrUnknownRole) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if err != nil {
+		h.App.Logger.ErrorContext(c.Request.Context(), "Error creating api key", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to create api key"})
+		return
+	}
+
+	if principal, ok := auth.PrincipalFrom(c); ok {
+		h.App.Logger.InfoContext(c.Request.Context(), "API key created", "name", resp.Name, "prefix", resp.Prefix, "roles", req.Roles, "actor", principal.Subject)
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *Handler) GetAPIKeys(c *gin.Context) {
+
+	keys, err := h.App.Model.APIKey.Get()
+	if err != nil {
+		h.App.Logger.ErrorContext(c.Request.Context(), "Error retrieving api keys", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to retrieve api keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+func (h *Handler) RevokeAPIKey(c *gin.Context) {
+
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "API key Id is required"})
+		return
+	}
+
+	err := h.App.Model.APIKey.Revoke(id, time.Now())
+	if errors.Is(err, models.ErrAPIKeyNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Active API key not found"})
+		return
+	}
+	if err != nil {
+		h.App.Logger.ErrorContext(c.Request.Context(), "Error revoking api key", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to revoke api key"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/chungweeeei/Temporal-robot-project/internal/repository/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultTelemetryWindow = time.Hour
+	defaultTelemetryLimit  = 5000
+	maxTelemetryLimit      = 50000
+)
+
+type TelemetryResponse struct {
+	RobotID string                   `json:"robot_id"`
+	From    time.Time                `json:"from"`
+	To      time.Time                `json:"to"`
+	Samples []models.TelemetrySample `json:"samples"`
+}
+
+type PoseTrailResponse struct {
+	RunID     string                   `json:"run_id"`
+	RobotID   string                   `json:"robot_id"`
+	Status    string                   `json:"status"`
+	StartTime time.Time                `json:"start_time"`
+	EndTime   time.Time                `json:"end_time"`
+	Trail     []models.TelemetrySample `json:"trail"`
+	Truncated bool                     `json:"truncated"` // the trail is thinned to the limit
+}
+
+func parseTelemetryLimit(c *gin.Context) (int, bool) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultTelemetryLimit)))
+	if err != nil || limit <= 0 || limit > maxTelemetryLimit {
+		return 0, false
+	}
+	return limit, true
+}
+
+// GetRobotTelemetry returns the stored status samples of a robot, from and to are RFC 3339 times
+// and default to the last hour.
+func (h *Handler) GetRobotTelemetry(c *gin.Context) {
+
+	robotID := c.Param("id")
+	if robotID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Robot Id is required"})
+		return
+	}
+
+	to := time.Now()
+	if raw := c.Query("to"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid to, expected RFC 3339 time"})
+			return
+		}
+		to = parsed
+	}
+
+	from := to.Add(-defaultTelemetryWindow)
+	if raw := c.Query("from"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid from, expected RFC 3339 time"})
+			return
+		}
+		from = parsed
+	}
+
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "from must be before to"})
+		return
+	}
+
+	limit, ok := parseTelemetryLimit(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid limit"})
+		return
+	}
+
+	samples, err := h.App.Model.Telemetry.GetRange(robotID, from, to, limit)
+	if err != nil {
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to get robot telemetry", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to get robot telemetry"})
+		return
+	}
+
+	c.JSON(http.StatusOK, TelemetryResponse{
+		RobotID: robotID,
+		From:    from,
+		To:      to,
+		Samples: samples,
+	})
+}
+
+// GetWorkflowRecordTrail returns the path the robot took during a run, running runs end now. A run
+// with more than limit samples is thinned evenly across it, so the trail still reaches its end.
+func (h *Handler) GetWorkflowRecordTrail(c *gin.Context) {
+
+	runId := c.Param("run_id")
+	if runId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Run Id is required"})
+		return
+	}
+
+	limit, ok := parseTelemetryLimit(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid limit"})
+		return
+	}
+
+	execution, err := h.App.Model.Execution.GetByRunID(runId)
+	if errors.Is(err, models.ErrExecutionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Workflow execution not found"})
+		return
+	}
+	if err != nil {
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to get workflow execution", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to get workflow execution"})
+		return
+	}
+
+	endTime := time.Now()
+	if execution.EndTime != nil {
+		endTime = *execution.EndTime
+	}
+
+	trail, truncated, err := h.App.Model.Telemetry.GetTrail(execution.RobotID, execution.StartTime, endTime, limit)
+	if err != nil {
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to get pose trail", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to get pose trail"})
+		return
+	}
+
+	c.JSON(http.StatusOK, PoseTrailResponse{
+		RunID:     execution.RunID,
+		RobotID:   execution.RobotID,
+		Status:    execution.Status,
+		StartTime: execution.StartTime,
+		EndTime:   endTime,
+		Trail:     trail,
+		Truncated: truncated,
+	})
+}
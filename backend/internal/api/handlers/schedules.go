@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -18,7 +17,7 @@ type CreateScheduleRequest struct {
 	ScheduleID string `json:"schedule_id" binding:"required"`
 	WorkflowID string `json:"workflow_id" binding:"required"`
 	CronExpr   string `json:"cron_expr" binding:"required"` // e.g. "*/5 * * * *"
-	Timezone   string `json:"timezone"`                     // 預設 Asia/Taipei
+	Timezone   string `json:"timezone"`                     // 預設為設定檔的 timezone
 }
 
 type Range struct {
@@ -82,7 +81,7 @@ func (h *Handler) CreateSchedule(c *gin.Context) {
 
 	var req CreateScheduleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.App.ErrorLog.Println("Invalid payload:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Invalid payload", "error", err)
 		c.JSON(http.StatusBadRequest,
 			gin.H{"message": fmt.Sprintf("Invalid payload: %v", err)})
 		return
@@ -91,12 +90,12 @@ func (h *Handler) CreateSchedule(c *gin.Context) {
 	// default timezone setting
 	timezone := req.Timezone
 	if timezone == "" {
-		timezone = "Asia/Taipei"
+		timezone = h.App.Settings.Timezone
 	}
 
 	_, err := time.LoadLocation(timezone)
 	if err != nil {
-		h.App.ErrorLog.Println("Invalid timezone:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Invalid timezone", "error", err)
 		c.JSON(http.StatusBadRequest,
 			gin.H{"message": "Invalid timezone"})
 		return
@@ -105,14 +104,21 @@ func (h *Handler) CreateSchedule(c *gin.Context) {
 	// check workflow id existence
 	record, err := h.App.Model.Workflow.GetByID(req.WorkflowID)
 	if err != nil {
-		h.App.ErrorLog.Println("Unable to get workflow:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to get workflow", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to get workflow"})
 		return
 	}
 
 	var nodes map[string]pkg.WorkflowNode
 	if err := json.Unmarshal([]byte(record.Nodes), &nodes); err != nil {
-		h.App.ErrorLog.Println("Unable to unmarshal nodes:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to unmarshal nodes", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to process workflow data"})
+		return
+	}
+
+	batteryPolicy, err := decodeBatteryPolicy(record.BatteryPolicy)
+	if err != nil {
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to unmarshal battery policy", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to process workflow data"})
 		return
 	}
@@ -121,7 +127,7 @@ func (h *Handler) CreateSchedule(c *gin.Context) {
 	scheduleClient := h.App.TemporalClient.ScheduleClient()
 
 	// start creating schedule task
-	scheduleHandle, err := scheduleClient.Create(context.Background(), client.ScheduleOptions{
+	scheduleHandle, err := scheduleClient.Create(h.actorContext(c), client.ScheduleOptions{
 		ID: req.ScheduleID,
 		Spec: client.ScheduleSpec{
 			CronExpressions: []string{req.CronExpr},
@@ -134,18 +140,20 @@ func (h *Handler) CreateSchedule(c *gin.Context) {
 			// 註冊在 Temporal server 的 Workflow 名稱
 			Workflow: workflow.RobotWorkflow,
 			// 如果 TaskQueue 也是存在 DB，可以用 record.TaskQueue，否則這裡是寫死的
-			TaskQueue: "ROBOT_TASK_QUEUE",
+			TaskQueue: h.App.Settings.Temporal.TaskQueue,
 			Args: []interface{}{pkg.WorkflowPayload{
-				WorkflowID: record.WorkflowID,
-				RootNodeID: record.RootNodeID,
-				Nodes:      nodes,
+				WorkflowID:    record.WorkflowID,
+				ScheduleID:    req.ScheduleID,
+				RootNodeID:    record.RootNodeID,
+				Nodes:         nodes,
+				BatteryPolicy: batteryPolicy,
 			}},
 		},
 		Overlap: enums.SCHEDULE_OVERLAP_POLICY_SKIP,
 	})
 
 	if err != nil {
-		h.App.ErrorLog.Println("Unable to create schedule:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to create schedule", "error", err)
 		c.JSON(http.StatusInternalServerError,
 			gin.H{"message": "Unable to create schedule"})
 		return
@@ -163,11 +171,11 @@ func (h *Handler) GetSchedules(c *gin.Context) {
 
 	scheduleClient := h.App.TemporalClient.ScheduleClient()
 
-	listView, err := scheduleClient.List(context.Background(), client.ScheduleListOptions{
+	listView, err := scheduleClient.List(c.Request.Context(), client.ScheduleListOptions{
 		PageSize: 1,
 	})
 	if err != nil {
-		h.App.ErrorLog.Println("Unable to list schedules:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to list schedules", "error", err)
 		c.JSON(http.StatusInternalServerError,
 			gin.H{"message": "Unable to list schedules"})
 		return
@@ -177,7 +185,7 @@ func (h *Handler) GetSchedules(c *gin.Context) {
 	for listView.HasNext() {
 		scheduleEntry, err := listView.Next()
 		if err != nil {
-			h.App.ErrorLog.Println("Error iterating schedules:", err)
+			h.App.Logger.ErrorContext(c.Request.Context(), "Error iterating schedules", "error", err)
 			break
 		}
 
@@ -231,9 +239,9 @@ func (h *Handler) GetScheduleById(c *gin.Context) {
 	scheduleClient := h.App.TemporalClient.ScheduleClient()
 
 	scheduleHandle := scheduleClient.GetHandle(c, scheduleID)
-	scheduleInfo, err := scheduleHandle.Describe(context.Background())
+	scheduleInfo, err := scheduleHandle.Describe(c.Request.Context())
 	if err != nil {
-		h.App.ErrorLog.Println("Unable to describe schedule:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to describe schedule", "error", err)
 		c.JSON(http.StatusInternalServerError,
 			gin.H{"message": "Unable to describe schedule"})
 		return
@@ -283,11 +291,11 @@ func (h *Handler) PauseSchedule(c *gin.Context) {
 	scheduleClient := h.App.TemporalClient.ScheduleClient()
 
 	scheduleHandle := scheduleClient.GetHandle(c, scheduleID)
-	err := scheduleHandle.Pause(context.Background(), client.SchedulePauseOptions{
-		Note: "The Schedule has been paused.",
+	err := scheduleHandle.Pause(h.actorContext(c), client.SchedulePauseOptions{
+		Note: fmt.Sprintf("The Schedule has been paused by %s.", actorName(c)),
 	})
 	if err != nil {
-		h.App.ErrorLog.Println("Unable to pause schedule:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to pause schedule", "error", err)
 		c.JSON(http.StatusInternalServerError,
 			gin.H{"message": "Unable to pause schedule"})
 		return
@@ -310,11 +318,11 @@ func (h *Handler) ResumeSchedule(c *gin.Context) {
 	scheduleClient := h.App.TemporalClient.ScheduleClient()
 
 	scheduleHandle := scheduleClient.GetHandle(c, scheduleID)
-	err := scheduleHandle.Unpause(context.Background(), client.ScheduleUnpauseOptions{
-		Note: "The Schedule has been resumed.",
+	err := scheduleHandle.Unpause(h.actorContext(c), client.ScheduleUnpauseOptions{
+		Note: fmt.Sprintf("The Schedule has been resumed by %s.", actorName(c)),
 	})
 	if err != nil {
-		h.App.ErrorLog.Println("Unable to resume schedule:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to resume schedule", "error", err)
 		c.JSON(http.StatusInternalServerError,
 			gin.H{"message": "Unable to resume schedule"})
 		return
@@ -336,9 +344,9 @@ func (h *Handler) DeleteSchedule(c *gin.Context) {
 
 	scheduleClient := h.App.TemporalClient.ScheduleClient()
 	scheduleHandle := scheduleClient.GetHandle(c, scheduleID)
-	err := scheduleHandle.Delete(context.Background())
+	err := scheduleHandle.Delete(h.actorContext(c))
 	if err != nil {
-		h.App.ErrorLog.Println("Unable to delete schedule:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to delete schedule", "error", err)
 		c.JSON(http.StatusInternalServerError,
 			gin.H{"message": "Unable to delete schedule"})
 		return
@@ -360,7 +368,7 @@ func (h *Handler) UpdateSchedule(c *gin.Context) {
 
 	var req UpdateScheduleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.App.ErrorLog.Println("Invalid payload:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Invalid payload", "error", err)
 		c.JSON(http.StatusBadRequest,
 			gin.H{"message": fmt.Sprintf("Invalid payload: %v", err)})
 		return
@@ -385,11 +393,11 @@ func (h *Handler) UpdateSchedule(c *gin.Context) {
 		}, nil
 	}
 
-	err := scheduleHandle.Update(context.Background(), client.ScheduleUpdateOptions{
+	err := scheduleHandle.Update(h.actorContext(c), client.ScheduleUpdateOptions{
 		DoUpdate: updateSchedule,
 	})
 	if err != nil {
-		h.App.ErrorLog.Println("Unable to update schedule:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to update schedule", "error", err)
 		c.JSON(http.StatusInternalServerError,
 			gin.H{"message": "Unable to update schedule"})
 		return
@@ -1,39 +1,80 @@
 package handlers
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 
+	"github.com/chungweeeei/Temporal-robot-project/internal/logging"
 	"github.com/chungweeeei/Temporal-robot-project/internal/repository/models"
 	"github.com/chungweeeei/Temporal-robot-project/internal/workflow"
 	"github.com/chungweeeei/Temporal-robot-project/pkg"
 	"github.com/gin-gonic/gin"
 	"go.temporal.io/api/enums/v1"
-	"go.temporal.io/api/workflowservice/v1"
 	"go.temporal.io/sdk/client"
 )
 
 type SaveWorkflowRequest struct {
-	WorkflowID   string                 `json:"workflow_id" binding:"required"`
-	WorkflowName string                 `json:"workflow_name" binding:"required"`
-	Nodes        map[string]interface{} `json:"nodes"`
+	WorkflowID    string                 `json:"workflow_id" binding:"required"`
+	WorkflowName  string                 `json:"workflow_name" binding:"required"`
+	Nodes         map[string]interface{} `json:"nodes"`
+	BatteryPolicy *pkg.BatteryPolicy     `json:"battery_policy"`
 }
 
 type WorkflowRecord struct {
-	WorkflowID string `json:"workflow_id"`
-	RunID      string `json:"run_id"`
-	Status     string `json:"status"`
-	StartTime  string `json:"start_time"`
-	EndTime    string `json:"end_time"`
+	WorkflowID   string `json:"workflow_id"`
+	WorkflowName string `json:"workflow_name"`
+	ScheduleID   string `json:"schedule_id,omitempty"`
+	RobotID      string `json:"robot_id,omitempty"`
+	RunID        string `json:"run_id"`
+	Status       string `json:"status"`
+	Error        string `json:"error,omitempty"`
+	StartTime    string `json:"start_time"`
+	EndTime      string `json:"end_time"`
+}
+
+type WorkflowRecordDetail struct {
+	WorkflowRecord
+	Nodes []models.NodeExecution `json:"nodes"`
+}
+
+func toWorkflowRecord(execution models.ExecutionRecord) WorkflowRecord {
+	record := WorkflowRecord{
+		WorkflowID:   execution.WorkflowID,
+		WorkflowName: execution.WorkflowName,
+		ScheduleID:   execution.ScheduleID,
+		RobotID:      execution.RobotID,
+		RunID:        execution.RunID,
+		Status:       execution.Status,
+		Error:        execution.Error,
+		StartTime:    execution.StartTime.String(),
+	}
+	if execution.EndTime != nil {
+		record.EndTime = execution.EndTime.String()
+	}
+	return record
+}
+
+func decodeBatteryPolicy(raw []byte) (*pkg.BatteryPolicy, error) {
+
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var policy pkg.BatteryPolicy
+	if err := json.Unmarshal(raw, &policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
 }
 
 func (h *Handler) SaveWorkflow(c *gin.Context) {
 
 	var req SaveWorkflowRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.App.ErrorLog.Println("Invalid payload:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Invalid payload", "error", err)
 		c.JSON(http.StatusBadRequest,
 			gin.H{"message": fmt.Sprintf("Invalid payload: %v", err)})
 		return
@@ -41,22 +82,38 @@ func (h *Handler) SaveWorkflow(c *gin.Context) {
 
 	nodes, err := json.Marshal(req.Nodes)
 	if err != nil {
-		h.App.ErrorLog.Println("Unable to marshal nodes:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to marshal nodes", "error", err)
 		c.JSON(http.StatusInternalServerError,
 			gin.H{"message": "Unable to marshal nodes"})
 		return
 	}
 
+	var batteryPolicy []byte
+	if req.BatteryPolicy != nil {
+		if req.BatteryPolicy.CriticalLevel < 1 || req.BatteryPolicy.CriticalLevel > 100 {
+			c.JSON(http.StatusBadRequest,
+				gin.H{"message": "Battery policy critical level must be between 1 and 100"})
+			return
+		}
+		if _, exists := req.Nodes[req.BatteryPolicy.ReturnToDockNodeID]; !exists {
+			c.JSON(http.StatusBadRequest,
+				gin.H{"message": "Battery policy must reference an existing return to dock node"})
+			return
+		}
+		batteryPolicy, _ = json.Marshal(req.BatteryPolicy)
+	}
+
 	workflow := models.Workflow{
-		WorkflowID:   req.WorkflowID,
-		WorkflowName: req.WorkflowName,
-		RootNodeID:   "start",
-		Nodes:        nodes,
+		WorkflowID:    req.WorkflowID,
+		WorkflowName:  req.WorkflowName,
+		RootNodeID:    "start",
+		Nodes:         nodes,
+		BatteryPolicy: batteryPolicy,
 	}
 
 	id, err := h.App.Model.Workflow.Upsert(workflow)
 	if err != nil {
-		h.App.ErrorLog.Println("Unable to save workflow:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to save workflow", "error", err)
 		c.JSON(http.StatusInternalServerError,
 			gin.H{"message": "Unable to save workflow"})
 		return
@@ -78,7 +135,7 @@ func (h *Handler) TriggerWorkflow(c *gin.Context) {
 
 	record, err := h.App.Model.Workflow.GetByID(workflowId)
 	if err != nil {
-		h.App.ErrorLog.Println("Unable to get workflow:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to get workflow", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to get workflow"})
 		return
 	}
@@ -91,22 +148,31 @@ func (h *Handler) TriggerWorkflow(c *gin.Context) {
 	var nodes map[string]pkg.WorkflowNode
 	json.Unmarshal(nodesBytes, &nodes)
 
+	batteryPolicy, err := decodeBatteryPolicy(record.BatteryPolicy)
+	if err != nil {
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to unmarshal battery policy", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to process workflow data"})
+		return
+	}
+
 	var payload = pkg.WorkflowPayload{
-		WorkflowID: record.WorkflowID,
-		RootNodeID: record.RootNodeID,
-		Nodes:      nodes,
+		WorkflowID:    record.WorkflowID,
+		RootNodeID:    record.RootNodeID,
+		Nodes:         nodes,
+		BatteryPolicy: batteryPolicy,
 	}
 	workflowOptions := client.StartWorkflowOptions{
 		ID:        payload.WorkflowID,
-		TaskQueue: "ROBOT_TASK_QUEUE",
+		TaskQueue: h.App.Settings.Temporal.TaskQueue,
 	}
 
-	we, err := h.App.TemporalClient.ExecuteWorkflow(context.Background(), workflowOptions, workflow.RobotWorkflow, payload)
+	we, err := h.App.TemporalClient.ExecuteWorkflow(h.actorContext(c), workflowOptions, workflow.RobotWorkflow, payload)
 	if err != nil {
-		h.App.ErrorLog.Println("Unable to start workflow:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to start workflow", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to start workflow"})
 		return
 	}
+	h.App.Logger.InfoContext(c.Request.Context(), "Workflow started", logging.RunID, we.GetRunID())
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":     "Workflow started successfully",
@@ -122,9 +188,12 @@ func (h *Handler) PauseWorkflow(c *gin.Context) {
 		return
 	}
 
-	err := h.App.TemporalClient.SignalWorkflow(context.Background(), workflowID, "", "control-signal", "pause")
+	err := h.App.TemporalClient.SignalWorkflow(h.actorContext(c), workflowID, "", workflow.ControlSignalName, pkg.ControlSignal{
+		Action: "pause",
+		Actor:  actorName(c),
+	})
 	if err != nil {
-		h.App.ErrorLog.Println("Unable to signal workflow:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to signal workflow", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to signal workflow"})
 		return
 	}
@@ -143,9 +212,12 @@ func (h *Handler) ResumeWorkflow(c *gin.Context) {
 		return
 	}
 
-	err := h.App.TemporalClient.SignalWorkflow(context.Background(), workflowID, "", "control-signal", "resume")
+	err := h.App.TemporalClient.SignalWorkflow(h.actorContext(c), workflowID, "", workflow.ControlSignalName, pkg.ControlSignal{
+		Action: "resume",
+		Actor:  actorName(c),
+	})
 	if err != nil {
-		h.App.ErrorLog.Println("Unable to signal workflow:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to signal workflow", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to signal workflow"})
 		return
 	}
@@ -156,10 +228,71 @@ func (h *Handler) ResumeWorkflow(c *gin.Context) {
 	})
 }
 
+// CancelWorkflow cancels the running execution, the run is recorded as canceled.
+func (h *Handler) CancelWorkflow(c *gin.Context) {
+
+	workflowID := c.Param("id")
+	if workflowID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Workflow Id is required"})
+		return
+	}
+
+	err := h.App.TemporalClient.CancelWorkflow(h.actorContext(c), workflowID, "")
+	if err != nil {
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to cancel workflow", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to cancel workflow"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Cancel workflow",
+		"workflow_id": workflowID,
+	})
+}
+
+func (h *Handler) SendWorkflowSignal(c *gin.Context) {
+
+	workflowID := c.Param("id")
+	signalName := c.Param("name")
+	if workflowID == "" || signalName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Workflow Id and signal name are required"})
+		return
+	}
+
+	if signalName == workflow.ControlSignalName {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Use pause or resume to send control signals"})
+		return
+	}
+
+	// payload is optional and stored in workflow variables as-is
+	var payload interface{}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			h.App.Logger.ErrorContext(c.Request.Context(), "Invalid payload", "error", err)
+			c.JSON(http.StatusBadRequest,
+				gin.H{"message": fmt.Sprintf("Invalid payload: %v", err)})
+			return
+		}
+	}
+
+	err := h.App.TemporalClient.SignalWorkflow(h.actorContext(c), workflowID, "", signalName, payload)
+	if err != nil {
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to signal workflow", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to signal workflow"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Signal sent",
+		"workflow_id": workflowID,
+		"signal":      signalName,
+	})
+}
+
 func (h *Handler) GetWorkflows(c *gin.Context) {
 	workflows, err := h.App.Model.Workflow.Get()
 	if err != nil {
-		h.App.ErrorLog.Println("Unable to get workflows:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to get workflows", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to get workflows"})
 		return
 	}
@@ -177,7 +310,7 @@ func (h *Handler) GetWorkflowById(c *gin.Context) {
 
 	workflow, err := h.App.Model.Workflow.GetByID(workflow_id)
 	if err != nil {
-		h.App.ErrorLog.Println("Unable to get workflow:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to get workflow", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to get workflow"})
 		return
 	}
@@ -194,9 +327,9 @@ func (h *Handler) GetWorkflowStatus(c *gin.Context) {
 	}
 
 	// 1. First, get the system-level status from Temporal
-	descResp, err := h.App.TemporalClient.DescribeWorkflowExecution(context.Background(), workflowId, "")
+	descResp, err := h.App.TemporalClient.DescribeWorkflowExecution(c.Request.Context(), workflowId, "")
 	if err != nil {
-		h.App.ErrorLog.Println("Unable to describe workflow:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to describe workflow", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to get workflow status"})
 		return
 	}
@@ -211,7 +344,7 @@ func (h *Handler) GetWorkflowStatus(c *gin.Context) {
 		status = "Running"
 
 		// Only query for step if the workflow is actually running
-		queryResp, err := h.App.TemporalClient.QueryWorkflow(context.Background(), workflowId, "", "get_step")
+		queryResp, err := h.App.TemporalClient.QueryWorkflow(c.Request.Context(), workflowId, "", "get_step")
 		if err == nil {
 			if err := queryResp.Get(&currentStep); err == nil {
 				// If the internal logic says "Paused", we can override the status or just pass it as step
@@ -233,9 +366,10 @@ func (h *Handler) GetWorkflowStatus(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"status":       status,
-		"current_node": currentStep["nodeId"],
-		"current_step": currentStep["step"],
+		"status":         status,
+		"current_node":   currentStep["nodeId"],
+		"current_step":   currentStep["step"],
+		"waiting_signal": currentStep["signal"],
 	})
 }
 
@@ -249,7 +383,7 @@ func (h *Handler) DeleteWorkflow(c *gin.Context) {
 
 	err := h.App.Model.Workflow.Delete(workflowId)
 	if err != nil {
-		h.App.ErrorLog.Println("Unable to delete workflow:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to delete workflow", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to delete workflow"})
 		return
 	}
@@ -262,29 +396,55 @@ func (h *Handler) DeleteWorkflow(c *gin.Context) {
 
 func (h *Handler) GetWorkflowRecords(c *gin.Context) {
 
-	request := &workflowservice.ListWorkflowExecutionsRequest{
-		PageSize: 5,
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid limit"})
+		return
 	}
 
-	resp, err := h.App.TemporalClient.ListWorkflow(context.Background(), request)
+	executions, err := h.App.Model.Execution.GetRecords(limit)
 	if err != nil {
-		h.App.ErrorLog.Println("Unable to list workflow executions:", err)
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to list workflow executions", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to list workflow executions"})
 		return
 	}
 
-	var records []WorkflowRecord
-	for _, execution := range resp.Executions {
-		record := WorkflowRecord{
-			WorkflowID: execution.Execution.WorkflowId,
-			RunID:      execution.Execution.RunId,
-			Status:     execution.Status.String(),
-			StartTime:  execution.StartTime.AsTime().String(),
-			EndTime:    execution.CloseTime.AsTime().String(),
-		}
-
-		records = append(records, record)
+	records := []WorkflowRecord{}
+	for _, execution := range executions {
+		records = append(records, toWorkflowRecord(execution))
 	}
 
 	c.JSON(http.StatusOK, records)
 }
+
+func (h *Handler) GetWorkflowRecordByRunId(c *gin.Context) {
+
+	runId := c.Param("run_id")
+	if runId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Run Id is required"})
+		return
+	}
+
+	execution, err := h.App.Model.Execution.GetByRunID(runId)
+	if errors.Is(err, models.ErrExecutionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Workflow execution not found"})
+		return
+	}
+	if err != nil {
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to get workflow execution", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to get workflow execution"})
+		return
+	}
+
+	nodes, err := h.App.Model.Execution.GetNodes(runId)
+	if err != nil {
+		h.App.Logger.ErrorContext(c.Request.Context(), "Unable to get node executions", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to get node executions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, WorkflowRecordDetail{
+		WorkflowRecord: toWorkflowRecord(*execution),
+		Nodes:          nodes,
+	})
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"context"
+
+	config "github.com/chungweeeei/Temporal-robot-project/internal/config/api"
+	"github.com/chungweeeei/Temporal-robot-project/internal/health"
+	"go.temporal.io/sdk/client"
+)
+
+// readinessChecker reports whether requests can be served: Postgres, the Temporal frontend and its schedule API.
+func readinessChecker(app *config.AppConfig) *health.Checker {
+
+	checker := health.NewChecker()
+
+	checker.Add("database", func(ctx context.Context) error {
+		sqlDB, err := app.DB.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+
+	checker.Add("temporal", func(ctx context.Context) error {
+		_, err := app.TemporalClient.CheckHealth(ctx, &client.CheckHealthRequest{})
+		return err
+	})
+
+	// listing one schedule checks the namespace and the permissions of the client too
+	checker.Add("schedules", func(ctx context.Context) error {
+		iterator, err := app.TemporalClient.ScheduleClient().List(ctx, client.ScheduleListOptions{PageSize: 1})
+		if err != nil {
+			return err
+		}
+		if iterator.HasNext() {
+			_, err = iterator.Next()
+		}
+		return err
+	})
+
+	return checker
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/chungweeeei/Temporal-robot-project/internal/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// routeLogFields names the workflow, run or robot a request addresses in its log lines.
+func routeLogFields(c *gin.Context) {
+
+	ctx := c.Request.Context()
+	route := c.FullPath()
+	switch {
+	case strings.HasPrefix(route, "/api/v1/workflows/records/:run_id"):
+		ctx = logging.With(ctx, logging.RunID, c.Param("run_id"))
+	case strings.HasPrefix(route, "/api/v1/workflows/:id"):
+		ctx = logging.With(ctx, logging.WorkflowID, c.Param("id"))
+	case strings.HasPrefix(route, "/api/v1/robots/:id"):
+		ctx = logging.With(ctx, logging.RobotID, c.Param("id"))
+	}
+	c.Request = c.Request.WithContext(ctx)
+
+	c.Next()
+}
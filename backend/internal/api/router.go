@@ -1,54 +1,141 @@
 package api
 
 import (
+	"os"
 	"time"
 
 	api "github.com/chungweeeei/Temporal-robot-project/internal/api/handlers"
+	"github.com/chungweeeei/Temporal-robot-project/internal/auth"
 	config "github.com/chungweeeei/Temporal-robot-project/internal/config/api"
+	"github.com/chungweeeei/Temporal-robot-project/internal/config/settings"
+	"github.com/chungweeeei/Temporal-robot-project/internal/health"
+	"github.com/chungweeeei/Temporal-robot-project/internal/logging"
+	"github.com/chungweeeei/Temporal-robot-project/internal/metrics"
 	"github.com/chungweeeei/Temporal-robot-project/internal/repository/dao"
+	"github.com/chungweeeei/Temporal-robot-project/internal/temporalclient"
+	"github.com/chungweeeei/Temporal-robot-project/internal/tracing"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"go.temporal.io/sdk/converter"
 )
 
 func NewRouter(app *config.AppConfig) *gin.Engine {
 
-	router := gin.Default()
-	router.Use(gin.Logger())
+	router := gin.New()
+	// client IPs of the audit log come from X-Forwarded-For of these proxies only
+	if err := router.SetTrustedProxies(app.Settings.REST.TrustedProxies); err != nil {
+		app.Logger.Error("Unable to configure trusted proxies", "error", err)
+		os.Exit(1)
+	}
+	router.Use(logging.GinMiddleware(app.Logger))
 	router.Use(gin.Recovery())
+	router.Use(metrics.GinMiddleware())
+	router.Use(tracing.GinMiddleware())
+	router.Use(routeLogFields)
 	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"https://*", "http://*", "http://localhost:5173"},
+		AllowOrigins:     app.Settings.REST.AllowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization", auth.APIKeyHeader, "X-Namespace", "traceparent", "tracestate", logging.RequestIDHeader},
+		ExposeHeaders:    []string{tracing.TraceIDHeader, logging.RequestIDHeader},
 		AllowCredentials: true,
 		MaxAge:           6 * time.Hour,
 	}))
 
 	h := api.NewHandler(app, dao.NewWorkflowDAO(app.DB))
 
+	// Probes and the Prometheus scrape endpoint, served without authentication
+	router.GET("/healthz", gin.WrapH(health.NewChecker()))
+	router.GET("/readyz", gin.WrapH(readinessChecker(app)))
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	// API keys are always accepted, JWT bearer tokens once configured
+	authenticators := []auth.Authenticator{&auth.APIKeyAuthenticator{Keys: app.Model.APIKey}}
+	if authConfig := app.Settings.REST.Auth; authConfig.JWTEnabled() {
+		jwtAuthenticator, err := auth.NewJWTAuthenticator(authConfig.JWTSecret, authConfig.JWKSFile, authConfig.JWTIssuer, authConfig.JWTAudience)
+		if err != nil {
+			app.Logger.Error("Unable to configure JWT authentication", "error", err)
+			os.Exit(1)
+		}
+		authenticators = append(authenticators, jwtAuthenticator)
+	}
+
 	apiV1 := router.Group("/api/v1")
+	apiV1.Use(auth.Middleware(app.Logger, authenticators...))
+
+	// Codec endpoint of the Temporal UI, decrypts payloads for authorized users
+	var codecHandler gin.HandlerFunc
+	if app.Settings.Temporal.DataConverter == settings.EncryptionDataConverter {
+		codec, err := temporalclient.NewEncryptionCodec(app.Settings.Temporal.Encryption)
+		if err != nil {
+			app.Logger.Error("Unable to configure payload codec", "error", err)
+			os.Exit(1)
+		}
+		codecHandler = gin.WrapH(converter.NewPayloadCodecHTTPHandler(codec))
+	}
+
+	// Viewers read status and records
+	viewer := apiV1.Group("", auth.RequireRole(auth.RoleViewer))
 	{
-		// Activities
-		apiV1.GET("/activities", h.GetActivities)
-
-		// Workflows for manually trigger
-		apiV1.POST("/workflows", h.SaveWorkflow)
-		apiV1.GET("/workflows", h.GetWorkflows)
-		apiV1.GET("/workflows/records", h.GetWorkflowRecords)
-		apiV1.GET("/workflows/:id", h.GetWorkflowById)
-		apiV1.GET("/workflows/:id/status", h.GetWorkflowStatus)
-		apiV1.POST("/workflows/:id/trigger", h.TriggerWorkflow)
-		apiV1.POST("/workflows/:id/pause", h.PauseWorkflow)
-		apiV1.POST("/workflows/:id/resume", h.ResumeWorkflow)
-		apiV1.DELETE("/workflows/:id", h.DeleteWorkflow)
-
-		// Schedules for scheduled trigger
-		apiV1.POST("/schedules", h.CreateSchedule)
-		apiV1.GET("/schedules", h.GetSchedules)
-		apiV1.GET("/schedules/:id", h.GetScheduleById)
-		apiV1.POST("/schedules/:id/pause", h.PauseSchedule)
-		apiV1.POST("/schedules/:id/resume", h.ResumeSchedule)
-		apiV1.DELETE("/schedules/:id", h.DeleteSchedule)
-		apiV1.PUT("/schedules/:id", h.UpdateSchedule)
+		viewer.GET("/activities", h.GetActivities)
+
+		viewer.GET("/workflows", h.GetWorkflows)
+		viewer.GET("/workflows/records", h.GetWorkflowRecords)
+		viewer.GET("/workflows/records/:run_id", h.GetWorkflowRecordByRunId)
+		viewer.GET("/workflows/records/:run_id/trail", h.GetWorkflowRecordTrail)
+		viewer.GET("/workflows/:id", h.GetWorkflowById)
+		viewer.GET("/workflows/:id/status", h.GetWorkflowStatus)
+
+		viewer.GET("/schedules", h.GetSchedules)
+		viewer.GET("/schedules/:id", h.GetScheduleById)
+
+		// Robot telemetry history
+		viewer.GET("/robots/:id/telemetry", h.GetRobotTelemetry)
+
+		if codecHandler != nil {
+			viewer.POST("/codec/decode", codecHandler)
+		}
+	}
+
+	// every mutating call below is recorded in the audit log, denied ones included
+	recordAudit := auditMiddleware(app.Model.Audit, app.Logger)
+
+	// Operators run workflows without changing them
+	operator := apiV1.Group("", recordAudit, auth.RequireRole(auth.RoleOperator))
+	{
+		operator.POST("/workflows/:id/trigger", h.TriggerWorkflow)
+		operator.POST("/workflows/:id/pause", h.PauseWorkflow)
+		operator.POST("/workflows/:id/resume", h.ResumeWorkflow)
+		operator.POST("/workflows/:id/cancel", h.CancelWorkflow)
+		operator.POST("/workflows/:id/signals/:name", h.SendWorkflowSignal)
+
+		operator.POST("/schedules/:id/pause", h.PauseSchedule)
+		operator.POST("/schedules/:id/resume", h.ResumeSchedule)
+
+		// the UI encodes signal and start inputs
+		if codecHandler != nil {
+			operator.POST("/codec/encode", codecHandler)
+		}
+	}
+
+	// Designers edit workflows and schedules
+	designer := apiV1.Group("", recordAudit, auth.RequireRole(auth.RoleDesigner))
+	{
+		designer.POST("/workflows", h.SaveWorkflow)
+		designer.DELETE("/workflows/:id", h.DeleteWorkflow)
+
+		designer.POST("/schedules", h.CreateSchedule)
+		designer.PUT("/schedules/:id", h.UpdateSchedule)
+		designer.DELETE("/schedules/:id", h.DeleteSchedule)
+	}
+
+	// Admins manage api keys and read the audit log
+	admin := apiV1.Group("", recordAudit, auth.RequireRole(auth.RoleAdmin))
+	{
+		admin.GET("/audit", h.GetAuditEvents)
+
+		admin.POST("/auth/keys", h.CreateAPIKey)
+		admin.GET("/auth/keys", h.GetAPIKeys)
+		admin.DELETE("/auth/keys/:id", h.RevokeAPIKey)
 	}
 
 	return router
@@ -0,0 +1,85 @@
+// Package audit carries the caller of a REST request into Temporal headers and workflows.
+package audit
+
+import (
+	"context"
+
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/workflow"
+)
+
+// ActorHeader is the Temporal header holding the caller of a start, signal or cancel request,
+// it shows up in the workflow history.
+const ActorHeader = "actor"
+
+type actorKey struct{}
+
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func ActorFrom(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// ActorFromWorkflow returns the caller that started the workflow.
+func ActorFromWorkflow(ctx workflow.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+type actorPropagator struct{}
+
+// NewActorPropagator moves the actor between Go contexts, workflow contexts and Temporal headers.
+func NewActorPropagator() workflow.ContextPropagator {
+	return actorPropagator{}
+}
+
+func (actorPropagator) Inject(ctx context.Context, writer workflow.HeaderWriter) error {
+	return injectActor(ActorFrom(ctx), writer)
+}
+
+func (actorPropagator) InjectFromWorkflow(ctx workflow.Context, writer workflow.HeaderWriter) error {
+	return injectActor(ActorFromWorkflow(ctx), writer)
+}
+
+func (actorPropagator) Extract(ctx context.Context, reader workflow.HeaderReader) (context.Context, error) {
+	actor, err := extractActor(reader)
+	if err != nil || actor == "" {
+		return ctx, err
+	}
+	return WithActor(ctx, actor), nil
+}
+
+func (actorPropagator) ExtractToWorkflow(ctx workflow.Context, reader workflow.HeaderReader) (workflow.Context, error) {
+	actor, err := extractActor(reader)
+	if err != nil || actor == "" {
+		return ctx, err
+	}
+	return workflow.WithValue(ctx, actorKey{}, actor), nil
+}
+
+func injectActor(actor string, writer workflow.HeaderWriter) error {
+	if actor == "" {
+		return nil
+	}
+	payload, err := converter.GetDefaultDataConverter().ToPayload(actor)
+	if err != nil {
+		return err
+	}
+	writer.Set(ActorHeader, payload)
+	return nil
+}
+
+func extractActor(reader workflow.HeaderReader) (string, error) {
+	payload, exists := reader.Get(ActorHeader)
+	if !exists {
+		return "", nil
+	}
+	var actor string
+	if err := converter.GetDefaultDataConverter().FromPayload(payload, &actor); err != nil {
+		return "", err
+	}
+	return actor, nil
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chungweeeei/Temporal-robot-project/internal/repository/models"
+)
+
+const (
+	APIKeyHeader = "X-API-Key"
+	apiKeyScheme = "rk"
+)
+
+// GenerateAPIKey returns a new key "rk_<prefix>_<secret>" and its stored form.
+// The plain key is shown to the caller once and never stored.
+func GenerateAPIKey() (plain, prefix, hash string, err error) {
+
+	prefixBytes := make([]byte, 4)
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return "", "", "", err
+	}
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", "", "", err
+	}
+
+	prefix = hex.EncodeToString(prefixBytes)
+	plain = fmt.Sprintf("%s_%s_%s", apiKeyScheme, prefix, base64.RawURLEncoding.EncodeToString(secretBytes))
+
+	return plain, prefix, HashAPIKey(plain), nil
+}
+
+// HashAPIKey hashes a key for storage. Keys carry 256 random bits, a plain SHA-256 is enough.
+func HashAPIKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+func parseAPIKey(plain string) (prefix string, ok bool) {
+	parts := strings.SplitN(plain, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyScheme || parts[1] == "" || parts[2] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// APIKeyAuthenticator accepts keys from the X-API-Key header or an "Authorization: ApiKey" header.
+type APIKeyAuthenticator struct {
+	Keys models.APIKeyInterface
+}
+
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+
+	plain := r.Header.Get(APIKeyHeader)
+	if plain == "" {
+		if value, found := strings.CutPrefix(r.Header.Get("Authorization"), "ApiKey "); found {
+			plain = value
+		}
+	}
+	if plain == "" {
+		return nil, ErrNoCredentials
+	}
+
+	prefix, ok := parseAPIKey(plain)
+	if !ok {
+		return nil, fmt.Errorf("%w: malformed api key", ErrInvalidCredentials)
+	}
+
+	key, err := a.Keys.GetByPrefix(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(HashAPIKey(plain))) != 1 {
+		return nil, fmt.Errorf("%w: api key %s does not match", ErrInvalidCredentials, prefix)
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil {
+		return nil, fmt.Errorf("%w: api key %s is revoked", ErrInvalidCredentials, prefix)
+	}
+	if key.ExpiresAt != nil && now.After(*key.ExpiresAt) {
+		return nil, fmt.Errorf("%w: api key %s expired", ErrInvalidCredentials, prefix)
+	}
+
+	// usage is tracked to the minute, no write on every request
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > time.Minute {
+		a.Keys.Touch(key.ID, now)
+	}
+
+	return &Principal{Subject: "api-key:" + key.Name, Method: "api_key", Roles: key.Roles}, nil
+}
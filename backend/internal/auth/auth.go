@@ -0,0 +1,64 @@
+// Package auth authenticates REST requests with API keys or JWT bearer tokens.
+package auth
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// ErrNoCredentials means the request carries no credentials this authenticator understands,
+	// the next authenticator gets a chance.
+	ErrNoCredentials      = errors.New("no credentials")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+const principalKey = "auth.principal"
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Subject string   `json:"subject"`
+	Method  string   `json:"method"` // api_key or jwt
+	Roles   []string `json:"roles,omitempty"`
+}
+
+// Authenticator checks one kind of credentials.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// Middleware accepts a request once one of the authenticators accepts its credentials.
+func Middleware(logger *slog.Logger, authenticators ...Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, authenticator := range authenticators {
+			principal, err := authenticator.Authenticate(c.Request)
+			if errors.Is(err, ErrNoCredentials) {
+				continue
+			}
+			if err != nil {
+				logger.WarnContext(c.Request.Context(), "Authentication failed", "error", err)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Invalid credentials"})
+				return
+			}
+
+			c.Set(principalKey, principal)
+			c.Next()
+			return
+		}
+
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Authentication required"})
+	}
+}
+
+// PrincipalFrom returns the caller authenticated by Middleware.
+func PrincipalFrom(c *gin.Context) (*Principal, bool) {
+	value, exists := c.Get(principalKey)
+	if !exists {
+		return nil, false
+	}
+	principal, ok := value.(*Principal)
+	return principal, ok
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Role grants a set of operations. Every role can read, operator and designer are separate:
+// site staff may pause a patrol without being able to edit it.
+type Role string
+
+const (
+	RoleViewer   Role = "viewer"   // read status and records
+	RoleOperator Role = "operator" // trigger, pause, resume and cancel workflows and schedules
+	RoleDesigner Role = "designer" // save and delete workflows and schedules
+	RoleAdmin    Role = "admin"    // everything, including robots and api keys
+)
+
+var Roles = []Role{RoleViewer, RoleOperator, RoleDesigner, RoleAdmin}
+
+func ValidRole(role string) bool {
+	return slices.Contains(Roles, Role(role))
+}
+
+// HasRole reports whether the principal may act as role.
+func (p *Principal) HasRole(role Role) bool {
+	for _, granted := range p.Roles {
+		switch Role(granted) {
+		case role, RoleAdmin:
+			return true
+		case RoleOperator, RoleDesigner:
+			if role == RoleViewer {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RequireRole rejects requests whose principal lacks role, it runs after Middleware.
+func RequireRole(role Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := PrincipalFrom(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Authentication required"})
+			return
+		}
+		if !principal.HasRole(role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "Requires the " + string(role) + " role"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -15,9 +15,46 @@ const (
 	RobotStatusID        = 1009
 	RobotMotionControlID = 1013
 	RobotTTSCommandID    = 1014
+	RobotChargeControlID = 1015
 )
 
 const (
-	StandUpActionID = 3
-	SitDownActionID = 4
+	StandUpActionID   = 3
+	StandDownActionID = 4
+	SitDownActionID   = 6
+)
+
+type Posture string
+
+const (
+	PostureStandUp   Posture = "STAND_UP"
+	PostureStandDown Posture = "STAND_DOWN"
+	PostureSitDown   Posture = "SIT_DOWN"
+)
+
+const (
+	DockActionID   = 1
+	ChargeActionID = 2
+)
+
+const (
+	// Move is refused below this battery level (percent)
+	DefaultMinMoveBatteryLevel = 20
+
+	// Move fails when the robot makes no progress for this many seconds
+	DefaultStuckTimeoutSeconds = 30
+
+	// Minimum displacement (m) counted as progress
+	StuckDistance = 0.05
+)
+
+const (
+	// A telemetry sample is stored at least this often (seconds) ...
+	DefaultTelemetryIntervalSeconds = 5
+
+	// ... or as soon as the robot moved this far (m), or its battery or mission changed
+	TelemetryMinDistance = 0.2
+
+	// Telemetry older than this many days is deleted
+	DefaultTelemetryRetentionDays = 7
 )
@@ -1,8 +1,9 @@
 package config
 
 import (
-	"log"
+	"log/slog"
 
+	"github.com/chungweeeei/Temporal-robot-project/internal/config/settings"
 	"github.com/chungweeeei/Temporal-robot-project/internal/database"
 	"go.temporal.io/sdk/client"
 	"gorm.io/gorm"
@@ -11,38 +12,35 @@ import (
 type AppConfig struct {
 	DB             *gorm.DB
 	Model          database.Models
-	InfoLog        *log.Logger
-	ErrorLog       *log.Logger
+	Logger         *slog.Logger
 	ErrorChan      chan error
 	ErrorDoneChan  chan bool
 	TemporalClient client.Client
+	Settings       *settings.Config
 }
 
-func NewAppConfig(db *gorm.DB, temporalClient client.Client) *AppConfig {
-	infoLog := log.New(log.Writer(), "[INFO]\t", log.Ldate|log.Ltime)
-	errorLog := log.New(log.Writer(), "[ERROR]\t", log.Ldate|log.Ltime|log.Lshortfile)
-
+func NewAppConfig(db *gorm.DB, temporalClient client.Client, settings *settings.Config, logger *slog.Logger) *AppConfig {
 	return &AppConfig{
 		DB:             db,
 		Model:          database.New(db),
-		InfoLog:        infoLog,
-		ErrorLog:       errorLog,
+		Logger:         logger,
 		ErrorChan:      make(chan error),
 		ErrorDoneChan:  make(chan bool),
 		TemporalClient: temporalClient,
+		Settings:       settings,
 	}
 }
 
 func (app *AppConfig) Shutdown() {
 
 	// perform any cleanup tasks
-	app.InfoLog.Println("Would run cleanup tasks...")
+	app.Logger.Info("Would run cleanup tasks...")
 
 	// notify "listenForErrors" channel to close
 	app.ErrorDoneChan <- true
 
 	// shutdown
-	app.InfoLog.Println("closing channels and shutting down application...")
+	app.Logger.Info("closing channels and shutting down application...")
 	close(app.ErrorChan)
 	close(app.ErrorDoneChan)
 }
@@ -0,0 +1,242 @@
+package settings
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv names the YAML settings file when -config is not given.
+const ConfigFileEnv = "ROBOT_PROJECT_CONFIG"
+
+// option binds one setting to its environment variable and flag.
+type option struct {
+	flag       string
+	env        string
+	usage      string
+	components []Component
+	field      func(c *Config) interface{} // *string, *int, *bool or *[]string into c
+}
+
+var (
+	backend = []Component{RESTServer, Worker}
+	all     = []Component{RESTServer, Worker, RobotServer}
+)
+
+var options = []option{
+	{"temporal-host-port", "TEMPORAL_HOST_PORT", "Temporal frontend address", backend,
+		func(c *Config) interface{} { return &c.Temporal.HostPort }},
+	{"temporal-namespace", "TEMPORAL_NAMESPACE", "Temporal namespace", backend,
+		func(c *Config) interface{} { return &c.Temporal.Namespace }},
+	{"temporal-task-queue", "TEMPORAL_TASK_QUEUE", "task queue of the robot workflows", backend,
+		func(c *Config) interface{} { return &c.Temporal.TaskQueue }},
+	{"temporal-api-key", "TEMPORAL_API_KEY", "Temporal API key", backend,
+		func(c *Config) interface{} { return &c.Temporal.APIKey }},
+	{"temporal-data-converter", "TEMPORAL_DATA_CONVERTER", "payload data converter, default, zlib or aes-gcm", backend,
+		func(c *Config) interface{} { return &c.Temporal.DataConverter }},
+	{"temporal-encryption-key-id", "TEMPORAL_ENCRYPTION_KEY_ID", "id of the payload encryption key", backend,
+		func(c *Config) interface{} { return &c.Temporal.Encryption.KeyID }},
+	{"temporal-encryption-key", "TEMPORAL_ENCRYPTION_KEY", "base64 AES key encrypting payloads", backend,
+		func(c *Config) interface{} { return &c.Temporal.Encryption.Key }},
+	{"temporal-tls", "TEMPORAL_TLS", "connect to Temporal with TLS", backend,
+		func(c *Config) interface{} { return &c.Temporal.TLS.Enabled }},
+	{"temporal-tls-ca-file", "TEMPORAL_TLS_CA_FILE", "CA certificate of the Temporal server", backend,
+		func(c *Config) interface{} { return &c.Temporal.TLS.CAFile }},
+	{"temporal-tls-cert-file", "TEMPORAL_TLS_CERT_FILE", "client certificate for mTLS", backend,
+		func(c *Config) interface{} { return &c.Temporal.TLS.CertFile }},
+	{"temporal-tls-key-file", "TEMPORAL_TLS_KEY_FILE", "client key for mTLS", backend,
+		func(c *Config) interface{} { return &c.Temporal.TLS.KeyFile }},
+	{"temporal-tls-server-name", "TEMPORAL_TLS_SERVER_NAME", "server name verified in the Temporal certificate", backend,
+		func(c *Config) interface{} { return &c.Temporal.TLS.ServerName }},
+
+	{"db-host", "DB_HOST", "Postgres host", backend,
+		func(c *Config) interface{} { return &c.Database.Host }},
+	{"db-port", "DB_PORT", "Postgres port", backend,
+		func(c *Config) interface{} { return &c.Database.Port }},
+	{"db-user", "DB_USER", "Postgres user", backend,
+		func(c *Config) interface{} { return &c.Database.User }},
+	{"db-password", "DB_PASSWORD", "Postgres password", backend,
+		func(c *Config) interface{} { return &c.Database.Password }},
+	{"db-name", "DB_NAME", "Postgres database, created when missing", backend,
+		func(c *Config) interface{} { return &c.Database.Name }},
+	{"db-sslmode", "DB_SSLMODE", "Postgres sslmode", backend,
+		func(c *Config) interface{} { return &c.Database.SSLMode }},
+	{"tracing-exporter", "TRACING_EXPORTER", "OpenTelemetry span exporter, none, stdout or otlp", backend,
+		func(c *Config) interface{} { return &c.Tracing.Exporter }},
+	{"tracing-otlp-endpoint", "OTEL_EXPORTER_OTLP_ENDPOINT", "OTLP/HTTP collector URL of the otlp exporter", backend,
+		func(c *Config) interface{} { return &c.Tracing.OTLPEndpoint }},
+	{"tracing-record-frames", "TRACING_RECORD_FRAMES", "store the plaintext rosbridge frames in span events", backend,
+		func(c *Config) interface{} { return &c.Tracing.RecordFrames }},
+	{"timezone", "TIMEZONE", "timezone of database sessions and schedules", backend,
+		func(c *Config) interface{} { return &c.Timezone }},
+
+	{"listen", "REST_LISTEN", "REST server listen address", []Component{RESTServer},
+		func(c *Config) interface{} { return &c.REST.Listen }},
+	{"cors-allowed-origins", "CORS_ALLOWED_ORIGINS", "comma separated origins allowed to call the REST API", []Component{RESTServer},
+		func(c *Config) interface{} { return &c.REST.AllowedOrigins }},
+	{"trusted-proxies", "REST_TRUSTED_PROXIES", "comma separated proxy IPs or CIDRs whose X-Forwarded-For is trusted", []Component{RESTServer},
+		func(c *Config) interface{} { return &c.REST.TrustedProxies }},
+	{"auth-jwt-secret", "AUTH_JWT_SECRET", "shared secret of HS256/384/512 bearer tokens", []Component{RESTServer},
+		func(c *Config) interface{} { return &c.REST.Auth.JWTSecret }},
+	{"auth-jwks-file", "AUTH_JWKS_FILE", "JWKS file with the public keys of bearer tokens", []Component{RESTServer},
+		func(c *Config) interface{} { return &c.REST.Auth.JWKSFile }},
+	{"auth-jwt-issuer", "AUTH_JWT_ISSUER", "required issuer of bearer tokens", []Component{RESTServer},
+		func(c *Config) interface{} { return &c.REST.Auth.JWTIssuer }},
+	{"auth-jwt-audience", "AUTH_JWT_AUDIENCE", "required audience of bearer tokens", []Component{RESTServer},
+		func(c *Config) interface{} { return &c.REST.Auth.JWTAudience }},
+
+	{"robot-ip", "ROBOT_IP", "robot address", []Component{Worker},
+		func(c *Config) interface{} { return &c.Worker.RobotIP }},
+	{"robot-port", "ROBOT_PORT", "robot websocket port", []Component{Worker},
+		func(c *Config) interface{} { return &c.Worker.RobotPort }},
+	{"robot-url", "ROBOT_URL", "robot websocket URL, e.g. ws://localhost:9090/robots/alpha", []Component{Worker},
+		func(c *Config) interface{} { return &c.Worker.RobotURL }},
+	{"robot-id", "ROBOT_ID", "robot id of execution records and telemetry", []Component{Worker},
+		func(c *Config) interface{} { return &c.Worker.RobotID }},
+	{"min-move-battery-level", "MIN_MOVE_BATTERY_LEVEL", "battery level (percent) below which moves are refused", []Component{Worker},
+		func(c *Config) interface{} { return &c.Worker.MinMoveBatteryLevel }},
+	{"move-stuck-timeout", "MOVE_STUCK_TIMEOUT", "seconds without progress before a move fails", []Component{Worker},
+		func(c *Config) interface{} { return &c.Worker.MoveStuckTimeout }},
+	{"telemetry-interval", "TELEMETRY_INTERVAL", "seconds between stored telemetry samples", []Component{Worker},
+		func(c *Config) interface{} { return &c.Worker.TelemetryInterval }},
+	{"telemetry-retention-days", "TELEMETRY_RETENTION_DAYS", "days telemetry is kept", []Component{Worker},
+		func(c *Config) interface{} { return &c.Worker.TelemetryRetentionDays }},
+	{"health-listen", "HEALTH_LISTEN", "address of the worker /healthz, /readyz and /metrics endpoints", []Component{Worker},
+		func(c *Config) interface{} { return &c.Worker.HealthListen }},
+	{"robot-lost-timeout", "ROBOT_LOST_TIMEOUT", "seconds without robot status before /healthz fails and the worker should be restarted", []Component{Worker},
+		func(c *Config) interface{} { return &c.Worker.RobotLostTimeout }},
+
+	{"listen", "SIMULATOR_LISTEN", "mock robot server listen address", []Component{RobotServer},
+		func(c *Config) interface{} { return &c.Simulator.Listen }},
+
+	{"log-format", "LOG_FORMAT", "log output, text or json", all,
+		func(c *Config) interface{} { return &c.Log.Format }},
+	{"log-level", "LOG_LEVEL", "lowest logged level, debug, info, warn or error", all,
+		func(c *Config) interface{} { return &c.Log.Level }},
+}
+
+// Load registers the component's settings as flags on fs next to the binary's own flags,
+// parses args and layers defaults, the YAML file, environment variables and the set flags.
+// It also adds -config and -print-config; with -print-config the caller prints and exits.
+func Load(fs *flag.FlagSet, args []string, component Component) (*Config, bool, error) {
+
+	configPath := fs.String("config", os.Getenv(ConfigFileEnv), "YAML settings file (env "+ConfigFileEnv+")")
+	printConfig := fs.Bool("print-config", false, "print the effective settings and exit")
+
+	flagValues := map[string]string{}
+	componentOptions := []option{}
+	for _, opt := range options {
+		if !slices.Contains(opt.components, component) {
+			continue
+		}
+		componentOptions = append(componentOptions, opt)
+		record := func(value string) error {
+			if err := set(opt.field(Default()), value); err != nil {
+				return err
+			}
+			flagValues[opt.flag] = value
+			return nil
+		}
+		// boolean flags also work without a value, e.g. -temporal-tls
+		if _, isBool := opt.field(Default()).(*bool); isBool {
+			fs.BoolFunc(opt.flag, usage(opt), record)
+		} else {
+			fs.Func(opt.flag, usage(opt), record)
+		}
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, false, err
+	}
+
+	config := Default()
+
+	if *configPath != "" {
+		raw, err := os.ReadFile(*configPath)
+		if err != nil {
+			return nil, false, err
+		}
+		// unknown keys are typos, not extensions
+		decoder := yaml.NewDecoder(bytes.NewReader(raw))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(config); err != nil {
+			return nil, false, fmt.Errorf("invalid settings file %s: %v", *configPath, err)
+		}
+	}
+
+	for _, opt := range componentOptions {
+		if value, exists := os.LookupEnv(opt.env); exists && value != "" {
+			if err := set(opt.field(config), value); err != nil {
+				return nil, false, fmt.Errorf("invalid %s: %v", opt.env, err)
+			}
+		}
+	}
+
+	for _, opt := range componentOptions {
+		if value, exists := flagValues[opt.flag]; exists {
+			set(opt.field(config), value)
+		}
+	}
+
+	if err := config.Validate(component); err != nil {
+		return nil, false, err
+	}
+
+	return config, *printConfig, nil
+}
+
+func usage(opt option) string {
+
+	var value string
+	switch field := opt.field(Default()).(type) {
+	case *string:
+		value = *field
+	case *int:
+		value = strconv.Itoa(*field)
+	case *bool:
+		if *field {
+			value = "true"
+		}
+	case *[]string:
+		value = strings.Join(*field, ",")
+	}
+
+	if value == "" {
+		return fmt.Sprintf("%s (env %s)", opt.usage, opt.env)
+	}
+	return fmt.Sprintf("%s (env %s, default %s)", opt.usage, opt.env, value)
+}
+
+func set(field interface{}, value string) error {
+	switch target := field.(type) {
+	case *string:
+		*target = value
+	case *int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		*target = parsed
+	case *bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+		*target = parsed
+	case *[]string:
+		list := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		*target = list
+	}
+	return nil
+}
@@ -0,0 +1,357 @@
+// Package settings holds the deployment settings of the REST server, the worker and the mock robot server.
+// Values are layered: defaults, then a YAML file, then environment variables, then flags.
+package settings
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	activity "github.com/chungweeeei/Temporal-robot-project/internal/config/activity"
+	"gopkg.in/yaml.v3"
+)
+
+// Component selects the settings a binary uses, only those are exposed as flags and validated.
+type Component string
+
+const (
+	RESTServer  Component = "rest-server"
+	Worker      Component = "robot-workflow"
+	RobotServer Component = "robot-server"
+)
+
+// EncryptionDataConverter names the data converter encrypting payloads with AES-GCM.
+const EncryptionDataConverter = "aes-gcm"
+
+// Span exporters of TracingConfig.
+const (
+	TracingNone   = "none"
+	TracingStdout = "stdout"
+	TracingOTLP   = "otlp"
+)
+
+type Config struct {
+	Temporal  TemporalConfig  `yaml:"temporal"`
+	Database  DatabaseConfig  `yaml:"database"`
+	REST      RESTConfig      `yaml:"rest"`
+	Worker    WorkerConfig    `yaml:"worker"`
+	Simulator SimulatorConfig `yaml:"simulator"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	Log       LogConfig       `yaml:"log"`
+	Timezone  string          `yaml:"timezone"` // database sessions and schedules without a timezone
+}
+
+type TemporalConfig struct {
+	HostPort      string            `yaml:"host_port"`
+	Namespace     string            `yaml:"namespace"`
+	TaskQueue     string            `yaml:"task_queue"`
+	APIKey        string            `yaml:"api_key"`        // sent as "Authorization: Bearer"
+	DataConverter string            `yaml:"data_converter"` // must match between rest-server and robot-workflow
+	Encryption    EncryptionConfig  `yaml:"encryption"`     // keys of the aes-gcm data converter
+	TLS           TemporalTLSConfig `yaml:"tls"`
+}
+
+// EncryptionConfig holds the AES keys of payload encryption. Payloads are encrypted with Key,
+// PreviousKeys still decrypt history written before a key rotation.
+type EncryptionConfig struct {
+	KeyID        string            `yaml:"key_id"`
+	Key          string            `yaml:"key"`           // base64, 16, 24 or 32 bytes
+	PreviousKeys map[string]string `yaml:"previous_keys"` // key id to base64 key
+}
+
+// TemporalTLSConfig enables TLS, a client certificate and key make it mTLS.
+type TemporalTLSConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	CAFile     string `yaml:"ca_file"` // system roots when empty
+	CertFile   string `yaml:"cert_file"`
+	KeyFile    string `yaml:"key_file"`
+	ServerName string `yaml:"server_name"` // defaults to the host of host_port
+}
+
+type DatabaseConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"sslmode"`
+}
+
+type RESTConfig struct {
+	Listen         string     `yaml:"listen"`
+	AllowedOrigins []string   `yaml:"allowed_origins"`
+	TrustedProxies []string   `yaml:"trusted_proxies"` // IPs or CIDRs whose X-Forwarded-For is used, none by default
+	Auth           AuthConfig `yaml:"auth"`
+}
+
+// AuthConfig selects how REST clients authenticate. API keys are always accepted,
+// JWT bearer tokens once a shared secret or a JWKS file is configured.
+type AuthConfig struct {
+	JWTSecret   string `yaml:"jwt_secret"`
+	JWKSFile    string `yaml:"jwks_file"`
+	JWTIssuer   string `yaml:"jwt_issuer"`
+	JWTAudience string `yaml:"jwt_audience"`
+}
+
+type WorkerConfig struct {
+	RobotIP                string `yaml:"robot_ip"`
+	RobotPort              int    `yaml:"robot_port"`
+	RobotURL               string `yaml:"robot_url"` // defaults to ws://<robot_ip>:<robot_port>/
+	RobotID                string `yaml:"robot_id"`  // defaults to robot_ip
+	MinMoveBatteryLevel    int    `yaml:"min_move_battery_level"`
+	MoveStuckTimeout       int    `yaml:"move_stuck_timeout"`       // seconds
+	TelemetryInterval      int    `yaml:"telemetry_interval"`       // seconds
+	TelemetryRetentionDays int    `yaml:"telemetry_retention_days"` // days
+	HealthListen           string `yaml:"health_listen"`            // /healthz, /readyz and /metrics
+	RobotLostTimeout       int    `yaml:"robot_lost_timeout"`       // seconds without robot status before /healthz fails
+}
+
+type SimulatorConfig struct {
+	Listen string `yaml:"listen"`
+}
+
+// LogConfig selects the output of the structured logs of every binary.
+type LogConfig struct {
+	Format string `yaml:"format"` // text or json
+	Level  string `yaml:"level"`  // debug, info, warn or error
+}
+
+// TracingConfig selects where OpenTelemetry spans go: nowhere, stdout for local use or an OTLP collector.
+type TracingConfig struct {
+	Exporter     string `yaml:"exporter"`      // none, stdout or otlp
+	OTLPEndpoint string `yaml:"otlp_endpoint"` // OTLP/HTTP URL, e.g. http://localhost:4318
+	// RecordFrames stores the rosbridge frames, TTS text and coordinates included, in span events.
+	// Off by default, the events only carry the frame size.
+	RecordFrames bool `yaml:"record_frames"`
+}
+
+func Default() *Config {
+	return &Config{
+		Temporal: TemporalConfig{
+			HostPort:      "localhost:7233",
+			Namespace:     "default",
+			TaskQueue:     "ROBOT_TASK_QUEUE",
+			DataConverter: "default",
+			Encryption: EncryptionConfig{
+				KeyID: "default",
+			},
+		},
+		// host and password have no default, they come from the config file or DB_HOST and DB_PASSWORD
+		Database: DatabaseConfig{
+			Port:    5432,
+			User:    "admin",
+			Name:    "robot_workflow",
+			SSLMode: "disable",
+		},
+		REST: RESTConfig{
+			Listen:         "localhost:3000",
+			AllowedOrigins: []string{"http://localhost:5173"},
+		},
+		Worker: WorkerConfig{
+			RobotIP:                "localhost",
+			RobotPort:              9090,
+			MinMoveBatteryLevel:    activity.DefaultMinMoveBatteryLevel,
+			MoveStuckTimeout:       activity.DefaultStuckTimeoutSeconds,
+			TelemetryInterval:      activity.DefaultTelemetryIntervalSeconds,
+			TelemetryRetentionDays: activity.DefaultTelemetryRetentionDays,
+			HealthListen:           "localhost:8091",
+			RobotLostTimeout:       60,
+		},
+		Simulator: SimulatorConfig{
+			Listen: "localhost:9090",
+		},
+		Tracing: TracingConfig{
+			Exporter:     TracingNone,
+			OTLPEndpoint: "http://localhost:4318",
+		},
+		Log: LogConfig{
+			Format: "text",
+			Level:  "info",
+		},
+		Timezone: "Asia/Taipei",
+	}
+}
+
+// Active reports whether the connection uses TLS, setting any certificate file turns it on.
+func (t TemporalTLSConfig) Active() bool {
+	return t.Enabled || t.CAFile != "" || t.CertFile != "" || t.KeyFile != ""
+}
+
+// Keys decodes the encryption keys by key id.
+func (e EncryptionConfig) Keys() (map[string][]byte, error) {
+
+	keys := map[string][]byte{}
+	decode := func(keyID string, encoded string) error {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("encryption key %q is not base64", keyID)
+		}
+		if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+			return fmt.Errorf("encryption key %q has %d bytes, expected 16, 24 or 32", keyID, len(key))
+		}
+		keys[keyID] = key
+		return nil
+	}
+
+	if e.KeyID == "" {
+		return nil, errors.New("encryption key id is required")
+	}
+	if err := decode(e.KeyID, e.Key); err != nil {
+		return nil, err
+	}
+	for keyID, encoded := range e.PreviousKeys {
+		if keyID == e.KeyID {
+			return nil, fmt.Errorf("encryption key %q is both current and previous", keyID)
+		}
+		if err := decode(keyID, encoded); err != nil {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
+
+func (a AuthConfig) JWTEnabled() bool {
+	return a.JWTSecret != "" || a.JWKSFile != ""
+}
+
+// DSN connects to dbName on the configured server, the maintenance database is "postgres".
+func (d DatabaseConfig) DSN(dbName string, timezone string) string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s TimeZone=%s",
+		d.Host, d.User, d.Password, dbName, d.Port, d.SSLMode, timezone)
+}
+
+// URL is the websocket URL of the robot the worker drives.
+func (w WorkerConfig) URL() string {
+	if w.RobotURL != "" {
+		return w.RobotURL
+	}
+	return fmt.Sprintf("ws://%s/", net.JoinHostPort(w.RobotIP, fmt.Sprint(w.RobotPort)))
+}
+
+// ID names the robot in execution records and telemetry.
+func (w WorkerConfig) ID() string {
+	if w.RobotID != "" {
+		return w.RobotID
+	}
+	return w.RobotIP
+}
+
+var databaseName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Validate checks the settings component uses.
+func (c *Config) Validate(component Component) error {
+
+	problems := []string{}
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	if component == RESTServer || component == Worker {
+		_, _, err := net.SplitHostPort(c.Temporal.HostPort)
+		check(err == nil, "temporal.host_port %q must be host:port", c.Temporal.HostPort)
+		check(c.Temporal.Namespace != "", "temporal.namespace is required")
+		check(c.Temporal.TaskQueue != "", "temporal.task_queue is required")
+		if c.Temporal.DataConverter == EncryptionDataConverter {
+			_, err := c.Temporal.Encryption.Keys()
+			check(err == nil, "temporal.encryption: %v", err)
+		}
+		check((c.Temporal.TLS.CertFile == "") == (c.Temporal.TLS.KeyFile == ""), "temporal.tls.cert_file and temporal.tls.key_file go together")
+
+		check(c.Database.Host != "", "database.host is required")
+		check(c.Database.Port > 0 && c.Database.Port < 65536, "database.port %d is out of range", c.Database.Port)
+		check(c.Database.User != "", "database.user is required")
+		check(c.Database.Password != "", "database.password is required")
+		check(databaseName.MatchString(c.Database.Name), "database.name %q must be a plain identifier", c.Database.Name)
+		check(c.Database.SSLMode != "", "database.sslmode is required")
+
+		_, err = time.LoadLocation(c.Timezone)
+		check(err == nil, "timezone %q is unknown", c.Timezone)
+
+		check(slices.Contains([]string{TracingNone, TracingStdout, TracingOTLP}, c.Tracing.Exporter),
+			"tracing.exporter %q must be none, stdout or otlp", c.Tracing.Exporter)
+		if c.Tracing.Exporter == TracingOTLP {
+			endpoint, err := url.Parse(c.Tracing.OTLPEndpoint)
+			check(err == nil && (endpoint.Scheme == "http" || endpoint.Scheme == "https") && endpoint.Host != "",
+				"tracing.otlp_endpoint %q must be an http:// or https:// URL", c.Tracing.OTLPEndpoint)
+		}
+	}
+
+	check(c.Log.Format == "text" || c.Log.Format == "json", "log.format %q must be text or json", c.Log.Format)
+	check(slices.Contains([]string{"debug", "info", "warn", "error"}, c.Log.Level), "log.level %q must be debug, info, warn or error", c.Log.Level)
+
+	switch component {
+	case RESTServer:
+		_, _, err := net.SplitHostPort(c.REST.Listen)
+		check(err == nil, "rest.listen %q must be host:port", c.REST.Listen)
+		check(len(c.REST.AllowedOrigins) > 0, "rest.allowed_origins needs at least one origin")
+		for _, origin := range c.REST.AllowedOrigins {
+			check(origin != "*" && !strings.HasSuffix(origin, "://*"), "rest.allowed_origins %q must name an origin, not a wildcard", origin)
+		}
+		for _, proxy := range c.REST.TrustedProxies {
+			_, _, cidrErr := net.ParseCIDR(proxy)
+			check(cidrErr == nil || net.ParseIP(proxy) != nil, "rest.trusted_proxies %q must be an IP or CIDR", proxy)
+		}
+
+	case Worker:
+		check(c.Worker.RobotIP != "" || c.Worker.RobotURL != "", "worker.robot_ip or worker.robot_url is required")
+		check(c.Worker.RobotPort > 0 && c.Worker.RobotPort < 65536, "worker.robot_port %d is out of range", c.Worker.RobotPort)
+		check(c.Worker.RobotURL == "" || strings.HasPrefix(c.Worker.RobotURL, "ws://") || strings.HasPrefix(c.Worker.RobotURL, "wss://"),
+			"worker.robot_url %q must be a ws:// or wss:// URL", c.Worker.RobotURL)
+		check(c.Worker.MinMoveBatteryLevel >= 0 && c.Worker.MinMoveBatteryLevel <= 100, "worker.min_move_battery_level must be 0-100")
+		check(c.Worker.MoveStuckTimeout > 0, "worker.move_stuck_timeout must be positive")
+		check(c.Worker.TelemetryInterval > 0, "worker.telemetry_interval must be positive")
+		check(c.Worker.TelemetryRetentionDays > 0, "worker.telemetry_retention_days must be positive")
+		_, _, err := net.SplitHostPort(c.Worker.HealthListen)
+		check(err == nil, "worker.health_listen %q must be host:port", c.Worker.HealthListen)
+		check(c.Worker.RobotLostTimeout > 0, "worker.robot_lost_timeout must be positive")
+
+	case RobotServer:
+		_, _, err := net.SplitHostPort(c.Simulator.Listen)
+		check(err == nil, "simulator.listen %q must be host:port", c.Simulator.Listen)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid %s settings:\n  %s", component, strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// Print writes the effective settings as YAML with secrets masked.
+func (c *Config) Print(w io.Writer) error {
+
+	masked := *c
+	if masked.Database.Password != "" {
+		masked.Database.Password = "******"
+	}
+	if masked.Temporal.APIKey != "" {
+		masked.Temporal.APIKey = "******"
+	}
+	if masked.Temporal.Encryption.Key != "" {
+		masked.Temporal.Encryption.Key = "******"
+	}
+	if len(masked.Temporal.Encryption.PreviousKeys) > 0 {
+		previousKeys := map[string]string{}
+		for keyID := range masked.Temporal.Encryption.PreviousKeys {
+			previousKeys[keyID] = "******"
+		}
+		masked.Temporal.Encryption.PreviousKeys = previousKeys
+	}
+	if masked.REST.Auth.JWTSecret != "" {
+		masked.REST.Auth.JWTSecret = "******"
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	defer encoder.Close()
+	return encoder.Encode(masked)
+}
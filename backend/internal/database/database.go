@@ -2,84 +2,100 @@ package database
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
+	"github.com/chungweeeei/Temporal-robot-project/internal/config/settings"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
-func InitDB() *gorm.DB {
+func InitDB(dbConfig settings.DatabaseConfig, timezone string) (*gorm.DB, error) {
 
-	ensureDatabaseExists()
+	ensureDatabaseExists(dbConfig, timezone)
 
-	conn := connectToDB()
-	if conn == nil {
-		log.Panic("Can not connect to database")
+	conn, err := connectToDB(dbConfig.DSN(dbConfig.Name, timezone))
+	if err != nil {
+		return nil, fmt.Errorf("can not connect to database %s: %w", dbConfig.Name, err)
 	}
 
-	return conn
+	return conn, nil
+}
+
+// gormConfig logs slow and failed queries through the slog default handler.
+func gormConfig() *gorm.Config {
+	return &gorm.Config{
+		Logger: logger.NewSlogLogger(slog.Default(), logger.Config{
+			SlowThreshold: 200 * time.Millisecond,
+			LogLevel:      logger.Warn,
+		}),
+	}
 }
 
-func ensureDatabaseExists() {
+func ensureDatabaseExists(dbConfig settings.DatabaseConfig, timezone string) {
 
-	dsn := "host=postgresql.robot-project.orb.local user=admin password=admin dbname=postgres port=5432 sslmode=disable TimeZone=Asia/Taipei"
+	dsn := dbConfig.DSN("postgres", timezone)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(dsn), gormConfig())
 	if err != nil {
-		log.Printf("Failed to connect to postgres database: %v", err)
+		slog.Error("Failed to connect to postgres database", "error", err)
+		return
 	}
 
-	var exists bool
-	sqlDB, _ := db.DB()
+	sqlDB, err := db.DB()
+	if err != nil {
+		slog.Error("Failed to get postgres connection", "error", err)
+		return
+	}
 	defer sqlDB.Close()
 
-	err = db.Raw("SELECT EXISTS(SELECT 1 FROM pg_database WHERE datname = 'robot_workflow')").Scan(&exists).Error
+	var exists bool
+	err = db.Raw("SELECT EXISTS(SELECT 1 FROM pg_database WHERE datname = ?)", dbConfig.Name).Scan(&exists).Error
 	if err != nil {
-		log.Printf("Failed to check database existence: %v", err)
+		slog.Error("Failed to check database existence", "error", err)
+		return
 	}
 
 	if exists {
-		fmt.Println("Database 'robot_workflow' already exists")
+		slog.Info("Database already exists", "database", dbConfig.Name)
 		return
 	}
 
-	err = db.Exec("CREATE DATABASE robot_workflow").Error
+	// the name is validated as a plain identifier, it can not be a bind parameter
+	err = db.Exec(fmt.Sprintf("CREATE DATABASE %s", dbConfig.Name)).Error
 	if err != nil {
-		fmt.Printf("Failed to create database: %v", err)
+		slog.Error("Failed to create database", "database", dbConfig.Name, "error", err)
 	} else {
-		fmt.Println("Database 'robot_workflow' created successfully")
+		slog.Info("Database created", "database", dbConfig.Name)
 	}
 }
 
-func connectToDB() *gorm.DB {
+func connectToDB(dsn string) (*gorm.DB, error) {
 
 	count := 0
 
-	dsn := "host=postgresql.robot-project.orb.local user=admin password=admin dbname=robot_workflow port=5432 sslmode=disable TimeZone=Asia/Taipei"
-
 	for {
-		connection, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+		connection, err := gorm.Open(postgres.Open(dsn), gormConfig())
 		if err != nil {
-			fmt.Println("Postgres not yet ready, retrying...")
+			slog.Warn("Postgres not yet ready, retrying...", "error", err)
 		} else {
 			DB, err := connection.DB()
 			if err != nil {
-				fmt.Println("Failed connect to database")
-				return nil
+				return nil, err
 			}
 			DB.SetMaxIdleConns(5)
 			DB.SetConnMaxLifetime(30 * time.Minute)
 
-			fmt.Println("Connected to Postgres database successfully")
-			return connection
+			slog.Info("Connected to Postgres database successfully")
+			return connection, nil
 		}
 
 		if count > 10 {
-			return nil
+			return nil, err
 		}
 
-		fmt.Println("Backing off for 1 second")
+		slog.Info("Backing off for 1 second")
 		time.Sleep(1 * time.Second)
 		count++
 	}
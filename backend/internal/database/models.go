@@ -1,7 +1,7 @@
 package database
 
 import (
-	"log"
+	"log/slog"
 
 	"github.com/chungweeeei/Temporal-robot-project/internal/repository/dao"
 	"github.com/chungweeeei/Temporal-robot-project/internal/repository/models"
@@ -15,18 +15,26 @@ func New(dbPool *gorm.DB) Models {
 	db = dbPool
 
 	// Do auto migration
-	err := db.AutoMigrate(&models.ActivityDefinition{}, &models.Workflow{})
+	err := db.AutoMigrate(&models.ActivityDefinition{}, &models.Workflow{}, &models.Execution{}, &models.NodeExecution{}, &models.TelemetrySample{}, &models.APIKey{}, &models.AuditEvent{})
 	if err != nil {
-		log.Println("Failed to auto migrate workflows table")
+		slog.Error("Failed to auto migrate tables", "error", err)
 	}
 
 	return Models{
-		Workflow: dao.NewWorkflowDAO(db),
-		Activity: dao.NewActivityDAO(db),
+		Workflow:  dao.NewWorkflowDAO(db),
+		Activity:  dao.NewActivityDAO(db),
+		Execution: dao.NewExecutionDAO(db),
+		Telemetry: dao.NewTelemetryDAO(db),
+		APIKey:    dao.NewAPIKeyDAO(db),
+		Audit:     dao.NewAuditDAO(db),
 	}
 }
 
 type Models struct {
-	Workflow models.WorkflowInterface
-	Activity models.ActivityInterface
+	Workflow  models.WorkflowInterface
+	Activity  models.ActivityInterface
+	Execution models.ExecutionInterface
+	Telemetry models.TelemetryInterface
+	APIKey    models.APIKeyInterface
+	Audit     models.AuditInterface
 }
@@ -0,0 +1,134 @@
+// Package logging sets up the structured slog output of every binary. Lines are correlated by
+// request, workflow, run, node and robot ID: the IDs travel in contexts and Temporal headers
+// and the handler adds them to each line logged with that context.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/chungweeeei/Temporal-robot-project/internal/config/settings"
+	"go.opentelemetry.io/otel/trace"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Correlation keys of every log line.
+const (
+	RequestID  = "request_id"
+	WorkflowID = "workflow_id"
+	RunID      = "run_id"
+	NodeID     = "node_id"
+	RobotID    = "robot_id"
+)
+
+// temporalKeys renames the tags of Temporal SDK log lines to the keys of this project.
+var temporalKeys = map[string]string{
+	"WorkflowID":   WorkflowID,
+	"RunID":        RunID,
+	"WorkflowType": "workflow_type",
+	"ActivityID":   "activity_id",
+	"ActivityType": "activity_type",
+	"TaskQueue":    "task_queue",
+	"Namespace":    "namespace",
+	"Attempt":      "attempt",
+	"Error":        "error",
+}
+
+// Setup makes a text or JSON handler the slog default, the standard log package included,
+// and returns its logger naming the component on every line.
+func Setup(config settings.LogConfig, component settings.Component) *slog.Logger {
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(config.Level)); err != nil {
+		level = slog.LevelInfo
+	}
+	options := &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if key, renamed := temporalKeys[attr.Key]; renamed && len(groups) == 0 {
+				attr.Key = key
+			}
+			return attr
+		},
+	}
+
+	var handler slog.Handler
+	if config.Format == "json" {
+		handler = slog.NewJSONHandler(os.Stderr, options)
+	} else {
+		handler = slog.NewTextHandler(os.Stderr, options)
+	}
+
+	logger := slog.New(contextHandler{handler}).With("component", string(component))
+	slog.SetDefault(logger)
+	return logger
+}
+
+// contextHandler adds the correlation IDs and the trace ID of the context to each record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	fields := FieldsFrom(ctx)
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		record.AddAttrs(slog.String(key, fields[key]))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+type fieldsKey struct{}
+
+// With returns a context whose log lines carry key=value.
+func With(ctx context.Context, key string, value string) context.Context {
+	fields := maps.Clone(FieldsFrom(ctx))
+	if fields == nil {
+		fields = map[string]string{}
+	}
+	fields[key] = value
+	return context.WithValue(ctx, fieldsKey{}, fields)
+}
+
+func FieldsFrom(ctx context.Context) map[string]string {
+	fields, _ := ctx.Value(fieldsKey{}).(map[string]string)
+	return fields
+}
+
+// WithWorkflow is With for workflow code, activities started from the returned context
+// receive the fields through the Temporal header.
+func WithWorkflow(ctx workflow.Context, key string, value string) workflow.Context {
+	fields := maps.Clone(FieldsFromWorkflow(ctx))
+	if fields == nil {
+		fields = map[string]string{}
+	}
+	fields[key] = value
+	return workflow.WithValue(ctx, fieldsKey{}, fields)
+}
+
+func FieldsFromWorkflow(ctx workflow.Context) map[string]string {
+	fields, _ := ctx.Value(fieldsKey{}).(map[string]string)
+	return fields
+}
+
+// keyvals flattens fields for the key/value loggers of the Temporal SDK.
+func keyvals(fields map[string]string) []interface{} {
+	list := make([]interface{}, 0, 2*len(fields))
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		list = append(list, key, fields[key])
+	}
+	return list
+}
@@ -0,0 +1,146 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/workflow"
+)
+
+// FieldsHeader is the Temporal header holding the correlation IDs, e.g. the request ID of the
+// REST call that started a workflow and the node ID of an activity.
+const FieldsHeader = "log-fields"
+
+// propagatedFields are the IDs Temporal does not know itself; workflow and run ID come with
+// the SDK loggers and the robot ID with the worker's logger.
+var propagatedFields = []string{RequestID, NodeID}
+
+// TemporalLogger routes the Temporal SDK logs through the slog handler.
+func TemporalLogger(logger *slog.Logger) log.Logger {
+	return log.NewStructuredLogger(logger)
+}
+
+type fieldsPropagator struct{}
+
+// NewPropagator moves the correlation IDs between Go contexts, workflow contexts and Temporal headers.
+func NewPropagator() workflow.ContextPropagator {
+	return fieldsPropagator{}
+}
+
+func (fieldsPropagator) Inject(ctx context.Context, writer workflow.HeaderWriter) error {
+	return injectFields(FieldsFrom(ctx), writer)
+}
+
+func (fieldsPropagator) InjectFromWorkflow(ctx workflow.Context, writer workflow.HeaderWriter) error {
+	return injectFields(FieldsFromWorkflow(ctx), writer)
+}
+
+func (fieldsPropagator) Extract(ctx context.Context, reader workflow.HeaderReader) (context.Context, error) {
+	fields, err := extractFields(reader)
+	if err != nil || len(fields) == 0 {
+		return ctx, err
+	}
+	return context.WithValue(ctx, fieldsKey{}, fields), nil
+}
+
+func (fieldsPropagator) ExtractToWorkflow(ctx workflow.Context, reader workflow.HeaderReader) (workflow.Context, error) {
+	fields, err := extractFields(reader)
+	if err != nil || len(fields) == 0 {
+		return ctx, err
+	}
+	return workflow.WithValue(ctx, fieldsKey{}, fields), nil
+}
+
+func injectFields(all map[string]string, writer workflow.HeaderWriter) error {
+	fields := map[string]string{}
+	for _, key := range propagatedFields {
+		if value, exists := all[key]; exists {
+			fields[key] = value
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	payload, err := converter.GetDefaultDataConverter().ToPayload(fields)
+	if err != nil {
+		return err
+	}
+	writer.Set(FieldsHeader, payload)
+	return nil
+}
+
+func extractFields(reader workflow.HeaderReader) (map[string]string, error) {
+	payload, exists := reader.Get(FieldsHeader)
+	if !exists {
+		return nil, nil
+	}
+	var fields map[string]string
+	if err := converter.GetDefaultDataConverter().FromPayload(payload, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// WorkerInterceptor adds the correlation IDs of the context to the loggers returned by
+// workflow.GetLogger and activity.GetLogger, which log without a context.
+type WorkerInterceptor struct {
+	interceptor.WorkerInterceptorBase
+}
+
+func (*WorkerInterceptor) InterceptActivity(ctx context.Context, next interceptor.ActivityInboundInterceptor) interceptor.ActivityInboundInterceptor {
+	i := &activityInbound{}
+	i.Next = next
+	return i
+}
+
+func (*WorkerInterceptor) InterceptWorkflow(ctx workflow.Context, next interceptor.WorkflowInboundInterceptor) interceptor.WorkflowInboundInterceptor {
+	i := &workflowInbound{}
+	i.Next = next
+	return i
+}
+
+type activityInbound struct {
+	interceptor.ActivityInboundInterceptorBase
+}
+
+func (a *activityInbound) Init(outbound interceptor.ActivityOutboundInterceptor) error {
+	o := &activityOutbound{}
+	o.Next = outbound
+	return a.Next.Init(o)
+}
+
+type activityOutbound struct {
+	interceptor.ActivityOutboundInterceptorBase
+}
+
+func (a *activityOutbound) GetLogger(ctx context.Context) log.Logger {
+	return withFields(a.Next.GetLogger(ctx), FieldsFrom(ctx))
+}
+
+type workflowInbound struct {
+	interceptor.WorkflowInboundInterceptorBase
+}
+
+func (w *workflowInbound) Init(outbound interceptor.WorkflowOutboundInterceptor) error {
+	o := &workflowOutbound{}
+	o.Next = outbound
+	return w.Next.Init(o)
+}
+
+type workflowOutbound struct {
+	interceptor.WorkflowOutboundInterceptorBase
+}
+
+func (w *workflowOutbound) GetLogger(ctx workflow.Context) log.Logger {
+	return withFields(w.Next.GetLogger(ctx), FieldsFromWorkflow(ctx))
+}
+
+func withFields(logger log.Logger, fields map[string]string) log.Logger {
+	if len(fields) == 0 {
+		return logger
+	}
+	return log.With(logger, keyvals(fields)...)
+}
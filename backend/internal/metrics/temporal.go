@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"errors"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.temporal.io/sdk/client"
+)
+
+// TemporalHandler is the Temporal SDK metrics handler writing into the Prometheus registry.
+// Counters get a _total and timers a _seconds suffix. The SDK emits some metrics with different
+// tags at different call sites while Prometheus needs fixed label names, so the first tag set
+// seen for a name defines its labels: missing tags are left empty and extra ones dropped.
+type TemporalHandler struct {
+	tags     map[string]string
+	families *temporalFamilies
+}
+
+type temporalFamilies struct {
+	mu         sync.Mutex
+	registerer prometheus.Registerer
+	byName     map[string]*temporalFamily
+}
+
+type temporalFamily struct {
+	labelNames []string
+	counter    *prometheus.CounterVec
+	gauge      *prometheus.GaugeVec
+	histogram  *prometheus.HistogramVec
+}
+
+type counterFunc func(int64)
+
+func (f counterFunc) Inc(delta int64) { f(delta) }
+
+type gaugeFunc func(float64)
+
+func (f gaugeFunc) Update(value float64) { f(value) }
+
+type timerFunc func(time.Duration)
+
+func (f timerFunc) Record(duration time.Duration) { f(duration) }
+
+// NewTemporalHandler registers the SDK metrics in Registry.
+func NewTemporalHandler() *TemporalHandler {
+	return &TemporalHandler{
+		tags: map[string]string{},
+		families: &temporalFamilies{
+			registerer: Registry,
+			byName:     map[string]*temporalFamily{},
+		},
+	}
+}
+
+func (h *TemporalHandler) WithTags(tags map[string]string) client.MetricsHandler {
+	merged := maps.Clone(h.tags)
+	for key, value := range tags {
+		merged[sanitize(key)] = value
+	}
+	return &TemporalHandler{tags: merged, families: h.families}
+}
+
+func (h *TemporalHandler) Counter(name string) client.MetricsCounter {
+	family := h.families.get(sanitize(name)+"_total", h.tags, func(fullName string, labelNames []string) prometheus.Collector {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{Name: fullName, Help: "Temporal SDK counter " + name + "."}, labelNames)
+	})
+	if family == nil || family.counter == nil {
+		return client.MetricsNopHandler.Counter(name)
+	}
+	counter := family.counter.With(family.labels(h.tags))
+	return counterFunc(func(delta int64) { counter.Add(float64(delta)) })
+}
+
+func (h *TemporalHandler) Gauge(name string) client.MetricsGauge {
+	family := h.families.get(sanitize(name), h.tags, func(fullName string, labelNames []string) prometheus.Collector {
+		return prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: fullName, Help: "Temporal SDK gauge " + name + "."}, labelNames)
+	})
+	if family == nil || family.gauge == nil {
+		return client.MetricsNopHandler.Gauge(name)
+	}
+	return gaugeFunc(family.gauge.With(family.labels(h.tags)).Set)
+}
+
+func (h *TemporalHandler) Timer(name string) client.MetricsTimer {
+	family := h.families.get(sanitize(name)+"_seconds", h.tags, func(fullName string, labelNames []string) prometheus.Collector {
+		return prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: fullName, Help: "Temporal SDK timer " + name + "."}, labelNames)
+	})
+	if family == nil || family.histogram == nil {
+		return client.MetricsNopHandler.Timer(name)
+	}
+	observer := family.histogram.With(family.labels(h.tags))
+	return timerFunc(func(duration time.Duration) { observer.Observe(duration.Seconds()) })
+}
+
+// get returns the family of name, registering it with the tag names on first use.
+// It returns nil when the name cannot be registered, e.g. it is taken by another metric.
+func (f *temporalFamilies) get(name string, tags map[string]string, create func(name string, labelNames []string) prometheus.Collector) *temporalFamily {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if family, exists := f.byName[name]; exists {
+		return family
+	}
+
+	labelNames := slices.Sorted(maps.Keys(tags))
+	collector := create(name, labelNames)
+	if err := f.registerer.Register(collector); err != nil {
+		var registered prometheus.AlreadyRegisteredError
+		if !errors.As(err, &registered) {
+			slog.Warn("Temporal metric not exported", "metric", name, "error", err)
+			f.byName[name] = nil
+			return nil
+		}
+		collector = registered.ExistingCollector
+	}
+
+	family := &temporalFamily{labelNames: labelNames}
+	switch vec := collector.(type) {
+	case *prometheus.CounterVec:
+		family.counter = vec
+	case *prometheus.GaugeVec:
+		family.gauge = vec
+	case *prometheus.HistogramVec:
+		family.histogram = vec
+	}
+	f.byName[name] = family
+	return family
+}
+
+func (f *temporalFamily) labels(tags map[string]string) prometheus.Labels {
+	labels := prometheus.Labels{}
+	for _, name := range f.labelNames {
+		labels[name] = tags[name]
+	}
+	return labels
+}
+
+// sanitize maps a Temporal metric or tag name onto the Prometheus name characters.
+func sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == ':' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,177 @@
+package metrics
+
+import (
+	"context"
+	"expvar"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/chungweeeei/Temporal-robot-project/internal/robot/status"
+	"github.com/chungweeeei/Temporal-robot-project/pkg"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/interceptor"
+)
+
+var (
+	workflowRunsStarted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "robot_workflow_runs_started_total",
+		Help: "Robot workflow runs started, by trigger (manual or schedule).",
+	}, []string{"trigger"})
+
+	workflowRunsCompleted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "robot_workflow_runs_completed_total",
+		Help: "Robot workflow runs finished, by status.",
+	}, []string{"status"})
+
+	activityDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "robot_activity_duration_seconds",
+		Help:    "Activity execution time by activity type.",
+		Buckets: []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600},
+	}, []string{"activity_type"})
+
+	activityFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "robot_activity_failures_total",
+		Help: "Failed activity attempts by activity type.",
+	}, []string{"activity_type"})
+
+	rosbridgeRoundTrip = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "rosbridge_round_trip_seconds",
+		Help:    "Time from sending a rosbridge service call to its response, by activity type.",
+		Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"activity_type"})
+
+	rosbridgeReconnects = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "rosbridge_reconnects_total",
+		Help: "Reconnects of the robot status subscription after it failed.",
+	})
+
+	robotBattery = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "robot_battery_level_percent",
+		Help: "Battery level of the last robot status.",
+	})
+
+	robotPoseX = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "robot_pose_x_meters",
+		Help: "X position of the last robot status.",
+	})
+
+	robotPoseY = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "robot_pose_y_meters",
+		Help: "Y position of the last robot status.",
+	})
+
+	robotPoseYaw = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "robot_pose_yaw_radians",
+		Help: "Heading of the last robot status, derived from the orientation quaternion.",
+	})
+)
+
+// RegisterWorker registers the workflow, activity and robot link metrics. The robot metrics
+// carry a robot_id label; statusAge reports the time since the last robot status.
+func RegisterWorker(robotID string, statusAge func() time.Duration) {
+
+	Registry.MustRegister(workflowRunsStarted, workflowRunsCompleted, activityDuration, activityFailures)
+
+	robot := prometheus.WrapRegistererWith(prometheus.Labels{"robot_id": robotID}, Registry)
+	robot.MustRegister(
+		rosbridgeRoundTrip,
+		rosbridgeReconnects,
+		robotBattery,
+		robotPoseX,
+		robotPoseY,
+		robotPoseYaw,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "robot_status_age_seconds",
+			Help: "Time since the last robot status message.",
+		}, func() float64 { return statusAge().Seconds() }),
+		decodeErrorCollector{},
+	)
+}
+
+// WorkflowRunStarted counts a run, scheduleID is empty for runs started through the API.
+func WorkflowRunStarted(scheduleID string) {
+	trigger := "manual"
+	if scheduleID != "" {
+		trigger = "schedule"
+	}
+	workflowRunsStarted.WithLabelValues(trigger).Inc()
+}
+
+func WorkflowRunCompleted(executionStatus pkg.ExecutionStatus) {
+	workflowRunsCompleted.WithLabelValues(string(executionStatus)).Inc()
+}
+
+func RosbridgeRoundTrip(activityType pkg.ActivityType, duration time.Duration) {
+	rosbridgeRoundTrip.WithLabelValues(string(activityType)).Observe(duration.Seconds())
+}
+
+func RosbridgeReconnect() {
+	rosbridgeReconnects.Inc()
+}
+
+// RobotStatus updates the battery and pose gauges from a decoded status.
+func RobotStatus(robotStatus status.RobotStatus) {
+
+	robotBattery.Set(float64(robotStatus.BatteryLevel))
+
+	position := robotStatus.Pose.Position
+	robotPoseX.Set(position.X)
+	robotPoseY.Set(position.Y)
+
+	q := robotStatus.Pose.Orientation
+	robotPoseYaw.Set(math.Atan2(2*(q.W*q.Z+q.X*q.Y), 1-2*(q.Y*q.Y+q.Z*q.Z)))
+}
+
+// decodeErrorCollector exports the expvar status.DecodeErrors counters.
+type decodeErrorCollector struct{}
+
+var decodeErrorsDesc = prometheus.NewDesc(
+	"robot_status_decode_errors_total",
+	"Robot status decode problems by field, \"message\" for dropped messages.",
+	[]string{"field"}, nil,
+)
+
+func (decodeErrorCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- decodeErrorsDesc
+}
+
+func (decodeErrorCollector) Collect(ch chan<- prometheus.Metric) {
+	status.DecodeErrors.Do(func(kv expvar.KeyValue) {
+		count, err := strconv.ParseFloat(kv.Value.String(), 64)
+		if err != nil {
+			return
+		}
+		ch <- prometheus.MustNewConstMetric(decodeErrorsDesc, prometheus.CounterValue, count, kv.Key)
+	})
+}
+
+// ActivityInterceptor records the duration and failures of every activity by its type.
+type ActivityInterceptor struct {
+	interceptor.WorkerInterceptorBase
+}
+
+func (*ActivityInterceptor) InterceptActivity(ctx context.Context, next interceptor.ActivityInboundInterceptor) interceptor.ActivityInboundInterceptor {
+	i := &activityInbound{}
+	i.Next = next
+	return i
+}
+
+type activityInbound struct {
+	interceptor.ActivityInboundInterceptorBase
+}
+
+func (a *activityInbound) ExecuteActivity(ctx context.Context, in *interceptor.ExecuteActivityInput) (interface{}, error) {
+
+	activityType := activity.GetInfo(ctx).ActivityType.Name
+	start := time.Now()
+
+	result, err := a.Next.ExecuteActivity(ctx, in)
+
+	activityDuration.WithLabelValues(activityType).Observe(time.Since(start).Seconds())
+	if err != nil {
+		activityFailures.WithLabelValues(activityType).Inc()
+	}
+	return result, err
+}
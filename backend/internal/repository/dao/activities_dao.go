@@ -3,7 +3,7 @@ package dao
 import (
 	"encoding/json"
 	"errors"
-	"log"
+	"log/slog"
 
 	"github.com/chungweeeei/Temporal-robot-project/internal/repository/models"
 	"gorm.io/datatypes"
@@ -25,9 +25,9 @@ func NewActivityDAO(db *gorm.DB) *ActivityDAO {
 	}).Create(&seeds).Error
 
 	if err != nil {
-		log.Printf("Failed to seed activities: %v", err)
+		slog.Error("Failed to seed activities", "error", err)
 	} else {
-		log.Println("Activities definitions synced (Upsert).")
+		slog.Info("Activities definitions synced (Upsert).")
 	}
 
 	// seed Activity Definitions
@@ -77,6 +77,26 @@ func getActivitySeeds() []models.ActivityDefinition {
 	}
 	headJSON, _ := json.Marshal(headSchema)
 
+	chargeSchema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"target": map[string]interface{}{"type": "number", "title": "Target Battery (%)", "step": 1, "default": 100},
+		},
+		"required": []string{"target"},
+	}
+	chargeJSON, _ := json.Marshal(chargeSchema)
+
+	waitForSignalSchema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"signal":   map[string]interface{}{"type": "string", "title": "Signal Name"},
+			"timeout":  map[string]interface{}{"type": "number", "title": "Timeout (milliseconds, 0 = no timeout)", "default": 0},
+			"variable": map[string]interface{}{"type": "string", "title": "Store Payload As"},
+		},
+		"required": []string{"signal"},
+	}
+	waitForSignalJSON, _ := json.Marshal(waitForSignalSchema)
+
 	return []models.ActivityDefinition{
 		{
 			Name:         "Move",
@@ -96,6 +116,12 @@ func getActivitySeeds() []models.ActivityDefinition {
 			NodeType:     "action",
 			InputSchema:  datatypes.JSON{},
 		},
+		{
+			Name:         "Standdown",
+			ActivityType: "Standdown",
+			NodeType:     "action",
+			InputSchema:  datatypes.JSON{},
+		},
 		{
 			Name:         "Sitdown",
 			ActivityType: "Sitdown",
@@ -114,6 +140,24 @@ func getActivitySeeds() []models.ActivityDefinition {
 			NodeType:     "action",
 			InputSchema:  datatypes.JSON(headJSON),
 		},
+		{
+			Name:         "Dock",
+			ActivityType: "Dock",
+			NodeType:     "action",
+			InputSchema:  datatypes.JSON{},
+		},
+		{
+			Name:         "Charge",
+			ActivityType: "Charge",
+			NodeType:     "action",
+			InputSchema:  datatypes.JSON(chargeJSON),
+		},
+		{
+			Name:         "Wait For Signal",
+			ActivityType: "WaitForSignal",
+			NodeType:     "action",
+			InputSchema:  datatypes.JSON(waitForSignalJSON),
+		},
 	}
 
 }
@@ -0,0 +1,80 @@
+package dao
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chungweeeei/Temporal-robot-project/internal/repository/models"
+	"gorm.io/gorm"
+)
+
+type APIKeyDAO struct {
+	DB *gorm.DB
+}
+
+func NewAPIKeyDAO(db *gorm.DB) *APIKeyDAO {
+	return &APIKeyDAO{
+		DB: db,
+	}
+}
+
+func (dao *APIKeyDAO) Create(key models.APIKey) error {
+
+	result := dao.DB.Create(&key)
+	if result.Error != nil {
+		return errors.New("failed to insert api key")
+	}
+
+	return nil
+}
+
+func (dao *APIKeyDAO) Get() ([]models.APIKey, error) {
+
+	keys := []models.APIKey{}
+	result := dao.DB.Order("created_at DESC").Find(&keys)
+	if result.Error != nil {
+		return nil, errors.New("failed to retrieve api keys")
+	}
+
+	return keys, nil
+}
+
+func (dao *APIKeyDAO) GetByPrefix(prefix string) (*models.APIKey, error) {
+
+	keys := []models.APIKey{}
+	result := dao.DB.Where("prefix = ?", prefix).Limit(1).Find(&keys)
+	if result.Error != nil {
+		return nil, errors.New("failed to retrieve api key")
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("api key with prefix %s not found", prefix)
+	}
+
+	return &keys[0], nil
+}
+
+func (dao *APIKeyDAO) Revoke(id string, revokedAt time.Time) error {
+
+	result := dao.DB.Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt)
+	if result.Error != nil {
+		return errors.New("failed to revoke api key")
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: id %s", models.ErrAPIKeyNotFound, id)
+	}
+
+	return nil
+}
+
+func (dao *APIKeyDAO) Touch(id string, usedAt time.Time) error {
+
+	result := dao.DB.Model(&models.APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt)
+	if result.Error != nil {
+		return errors.New("failed to update api key usage")
+	}
+
+	return nil
+}
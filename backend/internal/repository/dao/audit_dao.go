@@ -0,0 +1,57 @@
+package dao
+
+import (
+	"errors"
+
+	"github.com/chungweeeei/Temporal-robot-project/internal/repository/models"
+	"gorm.io/gorm"
+)
+
+type AuditDAO struct {
+	DB *gorm.DB
+}
+
+func NewAuditDAO(db *gorm.DB) *AuditDAO {
+	return &AuditDAO{
+		DB: db,
+	}
+}
+
+func (dao *AuditDAO) Insert(event models.AuditEvent) error {
+
+	result := dao.DB.Create(&event)
+	if result.Error != nil {
+		return errors.New("failed to insert audit event")
+	}
+
+	return nil
+}
+
+// Get returns the matching events, newest first.
+func (dao *AuditDAO) Get(filter models.AuditFilter) ([]models.AuditEvent, error) {
+
+	query := dao.DB.Model(&models.AuditEvent{})
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Resource != "" {
+		query = query.Where("resource = ?", filter.Resource)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("time >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("time <= ?", filter.To)
+	}
+
+	events := []models.AuditEvent{}
+	result := query.Order("time DESC").Limit(filter.Limit).Find(&events)
+	if result.Error != nil {
+		return nil, errors.New("failed to retrieve audit events")
+	}
+
+	return events, nil
+}
@@ -0,0 +1,111 @@
+package dao
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chungweeeei/Temporal-robot-project/internal/repository/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExecutionDAO struct {
+	DB *gorm.DB
+}
+
+func NewExecutionDAO(db *gorm.DB) *ExecutionDAO {
+	return &ExecutionDAO{
+		DB: db,
+	}
+}
+
+func (dao *ExecutionDAO) Start(execution models.Execution) error {
+
+	// Activities may be retried, so starting the same run twice must be idempotent
+	result := dao.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "run_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "start_time", "updated_at"}),
+	}).Create(&execution)
+
+	if result.Error != nil {
+		return errors.New("failed to insert execution")
+	}
+
+	return nil
+}
+
+func (dao *ExecutionDAO) Finish(runID string, status string, errMsg string, endTime time.Time) error {
+
+	result := dao.DB.Model(&models.Execution{}).
+		Where("run_id = ?", runID).
+		Updates(map[string]interface{}{
+			"status":   status,
+			"error":    errMsg,
+			"end_time": endTime,
+		})
+	if result.Error != nil {
+		return errors.New("failed to update execution")
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("execution with %s not found", runID)
+	}
+
+	return nil
+}
+
+func (dao *ExecutionDAO) InsertNode(node models.NodeExecution) error {
+
+	// a retried insert finds the row of its first attempt, a node visit is keyed by its start time
+	result := dao.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "run_id"}, {Name: "node_id"}, {Name: "start_time"}},
+		DoNothing: true,
+	}).Create(&node)
+	if result.Error != nil {
+		return errors.New("failed to insert node execution")
+	}
+
+	return nil
+}
+
+func (dao *ExecutionDAO) records() *gorm.DB {
+	return dao.DB.Model(&models.Execution{}).
+		Select("executions.*, workflows.workflow_name").
+		Joins("LEFT JOIN workflows ON workflows.workflow_id = executions.workflow_id")
+}
+
+func (dao *ExecutionDAO) GetRecords(limit int) ([]models.ExecutionRecord, error) {
+
+	records := []models.ExecutionRecord{}
+	result := dao.records().Order("executions.start_time DESC").Limit(limit).Scan(&records)
+	if result.Error != nil {
+		return nil, errors.New("failed to retrieve executions")
+	}
+
+	return records, nil
+}
+
+func (dao *ExecutionDAO) GetByRunID(runID string) (*models.ExecutionRecord, error) {
+
+	records := []models.ExecutionRecord{}
+	result := dao.records().Where("executions.run_id = ?", runID).Limit(1).Scan(&records)
+	if result.Error != nil {
+		return nil, errors.New("failed to retrieve execution by run id")
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: run %s", models.ErrExecutionNotFound, runID)
+	}
+
+	return &records[0], nil
+}
+
+func (dao *ExecutionDAO) GetNodes(runID string) ([]models.NodeExecution, error) {
+
+	nodes := []models.NodeExecution{}
+	result := dao.DB.Where("run_id = ?", runID).Order("start_time").Find(&nodes)
+	if result.Error != nil {
+		return nil, errors.New("failed to retrieve node executions")
+	}
+
+	return nodes, nil
+}
@@ -0,0 +1,92 @@
+package dao
+
+import (
+	"errors"
+	"time"
+
+	"github.com/chungweeeei/Temporal-robot-project/internal/repository/models"
+	"gorm.io/gorm"
+)
+
+type TelemetryDAO struct {
+	DB *gorm.DB
+}
+
+func NewTelemetryDAO(db *gorm.DB) *TelemetryDAO {
+	return &TelemetryDAO{
+		DB: db,
+	}
+}
+
+func (dao *TelemetryDAO) Insert(sample models.TelemetrySample) error {
+
+	result := dao.DB.Create(&sample)
+	if result.Error != nil {
+		return errors.New("failed to insert telemetry sample")
+	}
+
+	return nil
+}
+
+// GetRange returns the samples of a robot between from and to, oldest first.
+func (dao *TelemetryDAO) GetRange(robotID string, from, to time.Time, limit int) ([]models.TelemetrySample, error) {
+
+	samples := []models.TelemetrySample{}
+	result := dao.DB.Where("robot_id = ? AND time BETWEEN ? AND ?", robotID, from, to).
+		Order("time").
+		Limit(limit).
+		Find(&samples)
+	if result.Error != nil {
+		return nil, errors.New("failed to retrieve telemetry")
+	}
+
+	return samples, nil
+}
+
+// GetTrail returns at most limit samples of a robot between from and to, oldest first. A range
+// with more samples is thinned evenly, keeping its last sample, and reported as truncated.
+func (dao *TelemetryDAO) GetTrail(robotID string, from, to time.Time, limit int) ([]models.TelemetrySample, bool, error) {
+
+	inRange := func() *gorm.DB {
+		return dao.DB.Model(&models.TelemetrySample{}).
+			Where("robot_id = ? AND time BETWEEN ? AND ?", robotID, from, to)
+	}
+
+	var count int64
+	if result := inRange().Count(&count); result.Error != nil {
+		return nil, false, errors.New("failed to count telemetry")
+	}
+	if count <= int64(limit) {
+		samples, err := dao.GetRange(robotID, from, to, limit)
+		return samples, false, err
+	}
+
+	samples := []models.TelemetrySample{}
+	var result *gorm.DB
+	if limit == 1 {
+		result = inRange().Order("time DESC").Limit(1).Find(&samples)
+	} else {
+		// every step-th sample from the first one plus the last one is at most limit samples
+		step := (count-2)/int64(limit-1) + 1
+		numbered := inRange().Select("*, row_number() OVER (ORDER BY time) AS rn")
+		result = dao.DB.Table("(?) AS numbered", numbered).
+			Where("(rn - 1) % ? = 0 OR rn = ?", step, count).
+			Order("time").
+			Find(&samples)
+	}
+	if result.Error != nil {
+		return nil, false, errors.New("failed to retrieve telemetry")
+	}
+
+	return samples, true, nil
+}
+
+func (dao *TelemetryDAO) DeleteBefore(before time.Time) (int64, error) {
+
+	result := dao.DB.Where("time < ?", before).Delete(&models.TelemetrySample{})
+	if result.Error != nil {
+		return 0, errors.New("failed to delete telemetry")
+	}
+
+	return result.RowsAffected, nil
+}
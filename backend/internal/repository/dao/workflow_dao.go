@@ -3,6 +3,7 @@ package dao
 import (
 	"errors"
 	"fmt"
+	"log/slog"
 
 	"github.com/chungweeeei/Temporal-robot-project/internal/repository/models"
 	"gorm.io/gorm"
@@ -23,7 +24,7 @@ func (dao *WorkflowDAO) Upsert(workflow models.Workflow) (string, error) {
 
 	result := dao.DB.Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "workflow_id"}},
-		DoUpdates: clause.AssignmentColumns([]string{"workflow_name", "nodes", "updated_at"}),
+		DoUpdates: clause.AssignmentColumns([]string{"workflow_name", "nodes", "battery_policy", "updated_at"}),
 	}).Create(&workflow)
 
 	if result.Error != nil {
@@ -52,7 +53,7 @@ func (dao *WorkflowDAO) GetByID(id string) (*models.Workflow, error) {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, fmt.Errorf("workflow with %s not found", id)
 		}
-		fmt.Println(result.Error)
+		slog.Error("Failed to retrieve workflow", "workflow_id", id, "error", result.Error)
 		return nil, errors.New("failed to retrieve workflow by id")
 	}
 
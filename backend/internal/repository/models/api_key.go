@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// APIKey authenticates machine clients. Only the SHA-256 hash of the key is stored,
+// the prefix identifies the key without revealing it. Roles are the auth roles granted to the key.
+type APIKey struct {
+	ID         string                      `json:"id" gorm:"primaryKey"`
+	Name       string                      `json:"name" gorm:"not null"`
+	Prefix     string                      `json:"prefix" gorm:"uniqueIndex; not null"`
+	Hash       string                      `json:"-" gorm:"not null"`
+	Roles      datatypes.JSONSlice[string] `json:"roles" gorm:"type:json; not null"`
+	ExpiresAt  *time.Time                  `json:"expires_at"`
+	LastUsedAt *time.Time                  `json:"last_used_at"`
+	RevokedAt  *time.Time                  `json:"revoked_at"`
+	CreatedAt  time.Time                   `json:"created_at" gorm:"autoCreateTime"`
+}
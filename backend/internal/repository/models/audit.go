@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// AuditEvent records one mutating API call. The request body is kept as a SHA-256 hash only.
+type AuditEvent struct {
+	ID         int64     `json:"id" gorm:"primaryKey autoIncrement"`
+	Time       time.Time `json:"time" gorm:"index; not null"`
+	Actor      string    `json:"actor" gorm:"index; not null"`
+	AuthMethod string    `json:"auth_method"`
+	Action     string    `json:"action" gorm:"index; not null"` // handler name, e.g. PauseWorkflow
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Resource   string    `json:"resource"` // workflow, schedule or key id
+	IP         string    `json:"ip"`
+	BodyHash   string    `json:"body_hash"`
+	Status     int       `json:"status"`
+	Result     string    `json:"result"` // success or failure
+}
+
+// AuditFilter narrows an audit query, empty fields match everything.
+type AuditFilter struct {
+	Actor    string
+	Action   string
+	Resource string
+	From     time.Time
+	To       time.Time
+	Limit    int
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+type Execution struct {
+	RunID      string     `json:"run_id" gorm:"primaryKey"`
+	WorkflowID string     `json:"workflow_id" gorm:"index; not null"`
+	ScheduleID string     `json:"schedule_id" gorm:"index"`
+	RobotID    string     `json:"robot_id" gorm:"index"`
+	Status     string     `json:"status" gorm:"not null"`
+	Error      string     `json:"error"`
+	StartTime  time.Time  `json:"start_time" gorm:"not null"`
+	EndTime    *time.Time `json:"end_time"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type NodeExecution struct {
+	ID           int        `json:"id" gorm:"primaryKey autoIncrement"`
+	RunID        string     `json:"run_id" gorm:"index; uniqueIndex:idx_node_executions_run_node_start; not null"`
+	NodeID       string     `json:"node_id" gorm:"uniqueIndex:idx_node_executions_run_node_start; not null"`
+	ActivityType string     `json:"activity_type" gorm:"index; not null"`
+	Status       string     `json:"status" gorm:"not null"`
+	Result       string     `json:"result"`
+	Error        string     `json:"error"`
+	StartTime    time.Time  `json:"start_time" gorm:"uniqueIndex:idx_node_executions_run_node_start; not null"`
+	EndTime      *time.Time `json:"end_time"`
+	DurationMs   int64      `json:"duration_ms"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// ExecutionRecord is an execution joined with the name of the workflow it ran.
+type ExecutionRecord struct {
+	Execution
+	WorkflowName string `json:"workflow_name"`
+}
@@ -1,5 +1,16 @@
 package models
 
+import (
+	"errors"
+	"time"
+)
+
+// ErrExecutionNotFound is returned by GetByRunID for an unknown run.
+var ErrExecutionNotFound = errors.New("execution not found")
+
+// ErrAPIKeyNotFound is returned by Revoke for an unknown or already revoked key.
+var ErrAPIKeyNotFound = errors.New("active api key not found")
+
 type WorkflowInterface interface {
 	Upsert(workflow Workflow) (string, error)
 	Get() ([]Workflow, error)
@@ -10,3 +21,32 @@ type WorkflowInterface interface {
 type ActivityInterface interface {
 	Get() ([]ActivityDefinition, error)
 }
+
+type ExecutionInterface interface {
+	Start(execution Execution) error
+	Finish(runID string, status string, errMsg string, endTime time.Time) error
+	InsertNode(node NodeExecution) error
+	GetRecords(limit int) ([]ExecutionRecord, error)
+	GetByRunID(runID string) (*ExecutionRecord, error)
+	GetNodes(runID string) ([]NodeExecution, error)
+}
+
+type TelemetryInterface interface {
+	Insert(sample TelemetrySample) error
+	GetRange(robotID string, from, to time.Time, limit int) ([]TelemetrySample, error)
+	GetTrail(robotID string, from, to time.Time, limit int) ([]TelemetrySample, bool, error)
+	DeleteBefore(before time.Time) (int64, error)
+}
+
+type APIKeyInterface interface {
+	Create(key APIKey) error
+	Get() ([]APIKey, error)
+	GetByPrefix(prefix string) (*APIKey, error)
+	Revoke(id string, revokedAt time.Time) error
+	Touch(id string, usedAt time.Time) error
+}
+
+type AuditInterface interface {
+	Insert(event AuditEvent) error
+	Get(filter AuditFilter) ([]AuditEvent, error)
+}
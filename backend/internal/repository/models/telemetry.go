@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// TelemetrySample is one downsampled point of a robot's status stream.
+type TelemetrySample struct {
+	ID           int64     `json:"-" gorm:"primaryKey autoIncrement"`
+	RobotID      string    `json:"robot_id" gorm:"index:idx_telemetry_robot_time,priority:1; not null"`
+	Time         time.Time `json:"time" gorm:"index:idx_telemetry_robot_time,priority:2; not null"`
+	X            float64   `json:"x"`
+	Y            float64   `json:"y"`
+	Orientation  float64   `json:"orientation"` // degree
+	BatteryLevel int       `json:"battery_level"`
+	Charging     bool      `json:"charging"`
+	Docked       bool      `json:"docked"`
+	MissionID    string    `json:"mission_id"`
+	MissionCode  int       `json:"mission_code"`
+}
@@ -0,0 +1,173 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/chungweeeei/Temporal-robot-project/internal/robot/simulator"
+)
+
+// RegisterAdminRoutes serves the simulator control and inspection API under prefix, e.g. /admin.
+// It lets tests set up preconditions without driving the robot through websocket commands.
+func (h *RobotHandler) RegisterAdminRoutes(mux *http.ServeMux, prefix string) {
+	mux.HandleFunc("GET "+prefix+"/state", h.GetState)
+	mux.HandleFunc("POST "+prefix+"/teleport", h.Teleport)
+	mux.HandleFunc("PUT "+prefix+"/battery", h.SetBattery)
+	mux.HandleFunc("POST "+prefix+"/faults", h.TriggerFault)
+	mux.HandleFunc("POST "+prefix+"/reset", h.Reset)
+	mux.HandleFunc("GET "+prefix+"/subscriptions", h.ListSubscriptions)
+	mux.HandleFunc("GET "+prefix+"/missions", h.ListMissions)
+}
+
+type TeleportRequest struct {
+	X           float64 `json:"x"`
+	Y           float64 `json:"y"`
+	Orientation float64 `json:"orientation"` // degree
+	Landmark    string  `json:"landmark"`    // takes precedence over the pose
+	Dock        bool    `json:"dock"`        // place the robot docked on the docking station
+}
+
+type BatteryRequest struct {
+	Level    *float64 `json:"level"`
+	Charging bool     `json:"charging"`
+}
+
+// FaultRequest mirrors a scenario fault step, durations use Go syntax such as "500ms" or "5s".
+type FaultRequest struct {
+	Type     simulator.FaultType `json:"type"`
+	Count    int                 `json:"count"`
+	After    string              `json:"after"`
+	Duration string              `json:"duration"`
+	Delay    string              `json:"delay"`
+}
+
+func (h *RobotHandler) GetState(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"name":  h.bot.Name,
+		"state": h.bot.Snapshot(),
+	})
+}
+
+func (h *RobotHandler) Teleport(w http.ResponseWriter, r *http.Request) {
+
+	var request TeleportRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeMessage(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	var err error
+	switch {
+	case request.Dock:
+		err = h.bot.TeleportToDock()
+	case request.Landmark != "":
+		err = h.bot.TeleportToLandmark(request.Landmark)
+	default:
+		err = h.bot.Teleport(simulator.Pose{X: request.X, Y: request.Y, Orientation: request.Orientation})
+	}
+
+	if errors.Is(err, simulator.ErrRobotMoving) {
+		writeMessage(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeMessage(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Robot teleported",
+		"state":   h.bot.Snapshot(),
+	})
+}
+
+func (h *RobotHandler) SetBattery(w http.ResponseWriter, r *http.Request) {
+
+	var request BatteryRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Level == nil {
+		writeMessage(w, http.StatusBadRequest, "Invalid request payload, level is required")
+		return
+	}
+
+	if err := h.bot.SetBattery(*request.Level, request.Charging); err != nil {
+		writeMessage(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Battery level updated",
+		"state":   h.bot.Snapshot(),
+	})
+}
+
+func (h *RobotHandler) TriggerFault(w http.ResponseWriter, r *http.Request) {
+
+	var request FaultRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeMessage(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	step := simulator.FaultStep{Type: request.Type, Count: request.Count}
+	for _, field := range []struct {
+		value  string
+		target *time.Duration
+	}{
+		{request.After, &step.After},
+		{request.Duration, &step.Duration},
+		{request.Delay, &step.Delay},
+	} {
+		if field.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(field.value)
+		if err != nil {
+			writeMessage(w, http.StatusBadRequest, "Invalid duration: "+field.value)
+			return
+		}
+		*field.target = duration
+	}
+
+	if err := step.Validate(); err != nil {
+		writeMessage(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.bot.InjectFault(step)
+	writeMessage(w, http.StatusOK, "Fault injected")
+}
+
+func (h *RobotHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	h.bot.Reset()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Robot reset",
+		"state":   h.bot.Snapshot(),
+	})
+}
+
+func (h *RobotHandler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"subscriptions": h.Subscriptions(),
+	})
+}
+
+func (h *RobotHandler) ListMissions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"missions": h.bot.ActiveMissions(),
+	})
+}
+
+func writeMessage(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Admin response error", "error", err)
+	}
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/chungweeeei/Temporal-robot-project/internal/robot/simulator"
+	"github.com/chungweeeei/Temporal-robot-project/pkg"
+)
+
+type publishMessage struct {
+	Op    string      `json:"op"`
+	Topic string      `json:"topic"`
+	Msg   interface{} `json:"msg"`
+}
+
+type statusMessage struct {
+	Op    string `json:"op"`
+	ID    string `json:"id,omitempty"`
+	Level string `json:"level"`
+	Msg   string `json:"msg"`
+}
+
+// subscription publishes one topic to one connection.
+// Messages wait in a queue of queue_length, the oldest is dropped when the client falls behind.
+type subscription struct {
+	topic    simulator.Topic
+	interval time.Duration
+	queue    *messageQueue
+	done     chan struct{}
+	info     Subscription
+}
+
+func newSubscription(topic simulator.Topic, request pkg.RobotTopicRequest, client string) *subscription {
+
+	// throttle_rate is the minimum time between two messages, in milliseconds
+	interval := topic.Rate
+	if throttle := time.Duration(request.ThrottleRate) * time.Millisecond; throttle > interval {
+		interval = throttle
+	}
+
+	// queue_length 0 keeps the latest message only
+	queueLength := max(request.QueueLength, 1)
+
+	return &subscription{
+		topic:    topic,
+		interval: interval,
+		queue:    newMessageQueue(queueLength),
+		done:     make(chan struct{}),
+		info: Subscription{
+			Client:       client,
+			Topic:        topic.Name,
+			ThrottleRate: request.ThrottleRate,
+			QueueLength:  queueLength,
+			Since:        time.Now(),
+		},
+	}
+}
+
+// run samples the topic every interval and sends queued messages until the subscription is closed.
+func (h *RobotHandler) runSubscription(conn *SafeConn, sub *subscription) {
+
+	go func() {
+		for {
+			select {
+			case <-sub.done:
+				return
+			case <-sub.queue.ready:
+				for {
+					message, ok := sub.queue.pop()
+					if !ok {
+						break
+					}
+					if err := h.write(conn, message); err != nil {
+						h.bot.Logger.Warn("Publish error", "topic", sub.topic.Name, "error", err)
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(sub.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-sub.done:
+			return
+		case <-ticker.C:
+			// Injected fault: keep the connection but stop publishing
+			if h.bot.Faults.StatusFrozen() {
+				continue
+			}
+			sub.queue.push(publishMessage{
+				Op:    "publish",
+				Topic: sub.topic.Name,
+				Msg:   sub.topic.Message(h.bot),
+			})
+		}
+	}
+}
+
+type messageQueue struct {
+	mu    sync.Mutex
+	items []interface{}
+	size  int
+	ready chan struct{}
+}
+
+func newMessageQueue(size int) *messageQueue {
+	return &messageQueue{size: size, ready: make(chan struct{}, 1)}
+}
+
+func (q *messageQueue) push(message interface{}) {
+	q.mu.Lock()
+	if len(q.items) >= q.size {
+		q.items = q.items[1:]
+	}
+	q.items = append(q.items, message)
+	q.mu.Unlock()
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (q *messageQueue) pop() (interface{}, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) == 0 {
+		return nil, false
+	}
+	message := q.items[0]
+	q.items = q.items[1:]
+	return message, true
+}
@@ -2,7 +2,6 @@ package client
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 	"sync"
 	"time"
@@ -14,10 +13,66 @@ import (
 
 type RobotHandler struct {
 	bot *simulator.MockRobot
+
+	subsMu        sync.Mutex
+	subscriptions map[*SafeConn][]Subscription
+}
+
+// Subscription is a topic a websocket client subscribed to.
+type Subscription struct {
+	Client       string    `json:"client"`
+	Topic        string    `json:"topic"`
+	ThrottleRate int       `json:"throttle_rate"`
+	QueueLength  int       `json:"queue_length"`
+	Since        time.Time `json:"since"`
 }
 
 func NewRobotHandler(bot *simulator.MockRobot) *RobotHandler {
-	return &RobotHandler{bot: bot}
+	return &RobotHandler{
+		bot:           bot,
+		subscriptions: map[*SafeConn][]Subscription{},
+	}
+}
+
+// Subscriptions lists the topics every open connection subscribed to.
+func (h *RobotHandler) Subscriptions() []Subscription {
+	h.subsMu.Lock()
+	defer h.subsMu.Unlock()
+
+	subscriptions := []Subscription{}
+	for _, connSubs := range h.subscriptions {
+		subscriptions = append(subscriptions, connSubs...)
+	}
+	return subscriptions
+}
+
+func (h *RobotHandler) trackSubscription(conn *SafeConn, sub Subscription) {
+	h.subsMu.Lock()
+	defer h.subsMu.Unlock()
+	h.untrackTopicLocked(conn, sub.Topic)
+	h.subscriptions[conn] = append(h.subscriptions[conn], sub)
+}
+
+func (h *RobotHandler) untrackSubscription(conn *SafeConn, topic string) {
+	h.subsMu.Lock()
+	defer h.subsMu.Unlock()
+	h.untrackTopicLocked(conn, topic)
+}
+
+func (h *RobotHandler) untrackTopicLocked(conn *SafeConn, topic string) {
+	connSubs := h.subscriptions[conn][:0]
+	for _, sub := range h.subscriptions[conn] {
+		if sub.Topic != topic {
+			connSubs = append(connSubs, sub)
+		}
+	}
+	h.subscriptions[conn] = connSubs
+}
+
+func (h *RobotHandler) untrackConnection(conn *SafeConn) {
+	h.subsMu.Lock()
+	defer h.subsMu.Unlock()
+	delete(h.subscriptions, conn)
 }
 
 var (
@@ -47,6 +102,21 @@ func (c *SafeConn) WriteJSON(v interface{}) error {
 	return c.conn.WriteJSON(v)
 }
 
+func (c *SafeConn) WriteRaw(data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// write sends v unless an injected fault asks for a malformed message instead.
+func (h *RobotHandler) write(conn *SafeConn, v interface{}) error {
+	if h.bot.Faults.TakeMalformed() {
+		h.bot.Logger.Info("Sending malformed message (injected fault)")
+		return conn.WriteRaw([]byte(`{"op": "service_response", "values": {"data": `))
+	}
+	return conn.WriteJSON(v)
+}
+
 func (h *RobotHandler) HandleWS(w http.ResponseWriter, r *http.Request) {
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -55,16 +125,27 @@ func (h *RobotHandler) HandleWS(w http.ResponseWriter, r *http.Request) {
 	defer conn.Close()
 
 	safeConn := &SafeConn{conn: conn}
-	activeSubscriptions := make(map[string]chan struct{})
-	var subsMu sync.Mutex
+	// topic subscriptions of this connection, only touched by the read loop below
+	activeSubscriptions := make(map[string]*subscription)
+
+	// Injected fault: drop the connection, closing it unblocks the read loop below
+	closed := make(chan struct{})
+	defer close(closed)
+	go func() {
+		select {
+		case <-h.bot.Faults.Dropped():
+			h.bot.Logger.Info("Dropping websocket connection (injected fault)")
+			conn.Close()
+		case <-closed:
+		}
+	}()
 
 	// Clean up function
 	defer func() {
-		subsMu.Lock()
-		for _, cancel := range activeSubscriptions {
-			close(cancel)
+		h.untrackConnection(safeConn)
+		for _, sub := range activeSubscriptions {
+			close(sub.done)
 		}
-		subsMu.Unlock()
 	}()
 
 	for {
@@ -76,7 +157,7 @@ func (h *RobotHandler) HandleWS(w http.ResponseWriter, r *http.Request) {
 		// Step One: Check message header
 		var header MessageHeader
 		if err := json.Unmarshal(message, &header); err != nil {
-			log.Println("Invalid message format:", err)
+			h.bot.Logger.Warn("Invalid message format", "error", err)
 			continue
 		}
 
@@ -91,8 +172,12 @@ func (h *RobotHandler) HandleWS(w http.ResponseWriter, r *http.Request) {
 			go func(req pkg.RobotServiceRequest) {
 				response := h.bot.HandleRequest(req)
 
-				if err := safeConn.WriteJSON(response); err != nil {
-					log.Println("Write error:", err)
+				if delay := h.bot.Faults.ResponseDelay(); delay > 0 {
+					time.Sleep(delay)
+				}
+
+				if err := h.write(safeConn, response); err != nil {
+					h.bot.Logger.Warn("Write error", "error", err)
 				}
 			}(request)
 		case "subscribe":
@@ -101,44 +186,38 @@ func (h *RobotHandler) HandleWS(w http.ResponseWriter, r *http.Request) {
 				continue
 			}
 
-			subsMu.Lock()
-			defer subsMu.Unlock()
-
-			if _, exists := activeSubscriptions[request.Topic]; exists {
-				log.Println("Already subscribed to:", request.Topic)
+			topic, ok := simulator.LookupTopic(request.Topic)
+			if !ok {
+				h.bot.Logger.Warn("Unknown topic", "topic", request.Topic)
+				h.write(safeConn, statusMessage{Op: "status", ID: request.ID, Level: "error", Msg: "Unknown topic " + request.Topic})
 				continue
 			}
 
-			// trigger broadcaster based on topic
-			done := make(chan struct{})
-			activeSubscriptions[request.Topic] = done
-
-			switch request.Topic {
-			case "/api/info":
-				go h.RobotStatusBroadcaster(safeConn, done)
-			default:
-				log.Println("Unknown topic:", request.Topic)
-				close(done)
-				delete(activeSubscriptions, request.Topic)
+			// subscribing again replaces the previous throttle_rate and queue_length
+			if previous, exists := activeSubscriptions[request.Topic]; exists {
+				close(previous.done)
 			}
-		}
-	}
-}
 
-func (h *RobotHandler) RobotStatusBroadcaster(conn *SafeConn, done <-chan struct{}) {
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
+			sub := newSubscription(topic, request, r.RemoteAddr)
+			activeSubscriptions[request.Topic] = sub
+			h.trackSubscription(safeConn, sub.info)
+			go h.runSubscription(safeConn, sub)
 
-	for {
-		select {
-		case <-done:
-			return
-		case <-ticker.C:
-			status := h.bot.GetRobotStatus()
-			if err := conn.WriteJSON(status); err != nil {
-				log.Println("Broadcast error", err)
-				return
+		case "unsubscribe":
+			var request pkg.RobotTopicRequest
+			if err := json.Unmarshal(message, &request); err != nil {
+				continue
 			}
+
+			sub, exists := activeSubscriptions[request.Topic]
+			if !exists {
+				h.bot.Logger.Warn("Not subscribed to topic", "topic", request.Topic)
+				continue
+			}
+
+			close(sub.done)
+			delete(activeSubscriptions, request.Topic)
+			h.untrackSubscription(safeConn, request.Topic)
 		}
 	}
 }
@@ -0,0 +1,73 @@
+package recording
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  2048,
+	WriteBufferSize: 2048,
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+// Proxy forwards rosbridge connections to the robot and records every message in both directions.
+type Proxy struct {
+	Upstream string // robot websocket URL, e.g. ws://192.168.1.10:9090
+	Session  *Writer
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	upstreamURL := strings.TrimSuffix(p.Upstream, "/") + r.URL.RequestURI()
+	robotConn, _, err := websocket.DefaultDialer.DialContext(r.Context(), upstreamURL, nil)
+	if err != nil {
+		slog.Error("Unable to reach robot", "error", err)
+		http.Error(w, "robot unreachable", http.StatusBadGateway)
+		return
+	}
+	defer robotConn.Close()
+
+	clientConn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer clientConn.Close()
+
+	conn := p.Session.NewConn()
+	p.record(Message{Conn: conn, Path: r.URL.RequestURI(), Direction: DirectionOpen})
+	defer p.record(Message{Conn: conn, Direction: DirectionClose})
+
+	// whichever side closes first ends the session of both
+	done := make(chan struct{}, 2)
+	go p.pipe(conn, DirectionRobot, robotConn, clientConn, done)
+	go p.pipe(conn, DirectionClient, clientConn, robotConn, done)
+	<-done
+}
+
+func (p *Proxy) pipe(conn int, direction Direction, from, to *websocket.Conn, done chan<- struct{}) {
+
+	defer func() { done <- struct{}{} }()
+
+	for {
+		messageType, data, err := from.ReadMessage()
+		if err != nil {
+			return
+		}
+		p.record(newPayloadMessage(conn, direction, data))
+		if err := to.WriteMessage(messageType, data); err != nil {
+			return
+		}
+	}
+}
+
+func (p *Proxy) record(message Message) {
+	if err := p.Session.Write(message); err != nil {
+		slog.Error("Unable to record message", "error", err)
+	}
+}
@@ -0,0 +1,276 @@
+package recording
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Replayer stands in for the robot and serves a recorded session.
+//
+// Every incoming connection is matched to the next unused recorded connection with the same
+// first request. Robot messages are sent in recorded order and timing, and never before the
+// client requests that preceded them in the recording have arrived, on any connection.
+// This keeps status updates in step with commands however fast the workflow runs.
+type Replayer struct {
+	Speed float64 // 1 replays in recorded time, 2 twice as fast, 0 without delays
+
+	mu         sync.Mutex
+	conns      []*recordedConn
+	received   int
+	progressCh chan struct{}
+	missionIDs map[string]string // recorded mission id to live mission id
+}
+
+type recordedConn struct {
+	id      int
+	path    string
+	opened  time.Time
+	steps   []replayStep
+	claimed bool
+}
+
+type replayStep struct {
+	Message
+	barrier int // client messages recorded before this robot message, across connections
+}
+
+func NewReplayer(session *Session, speed float64) *Replayer {
+
+	replayer := &Replayer{
+		Speed:      speed,
+		progressCh: make(chan struct{}),
+		missionIDs: map[string]string{},
+	}
+
+	conns := map[int]*recordedConn{}
+	clientMessages := 0
+	for _, message := range session.Messages {
+		conn, exists := conns[message.Conn]
+		if !exists {
+			conn = &recordedConn{id: message.Conn, opened: message.Time}
+			conns[message.Conn] = conn
+			replayer.conns = append(replayer.conns, conn)
+		}
+
+		switch message.Direction {
+		case DirectionOpen:
+			conn.path = message.Path
+			conn.opened = message.Time
+		case DirectionClient:
+			conn.steps = append(conn.steps, replayStep{Message: message})
+			clientMessages++
+		case DirectionRobot:
+			conn.steps = append(conn.steps, replayStep{Message: message, barrier: clientMessages})
+		}
+	}
+
+	return replayer
+}
+
+func (r *Replayer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+
+	ws, err := upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+	defer ws.Close()
+
+	// client messages are read in the background so a closed connection also ends waiting
+	clientCh := make(chan []byte)
+	go func() {
+		defer close(clientCh)
+		for {
+			_, data, err := ws.ReadMessage()
+			if err != nil {
+				return
+			}
+			clientCh <- data
+		}
+	}()
+
+	first, ok := <-clientCh
+	if !ok {
+		return
+	}
+
+	conn := r.claim(req.URL.RequestURI(), first)
+	if conn == nil {
+		slog.Warn("No recorded connection left", "path", req.URL.RequestURI(), "request", requestKey(first))
+		return
+	}
+	slog.Info("Replaying recorded connection", "connection", conn.id, "request", requestKey(first))
+
+	pending := first
+	lastRecorded := conn.opened
+	lastSent := time.Now()
+
+	for _, step := range conn.steps {
+		switch step.Direction {
+		case DirectionClient:
+			live := pending
+			pending = nil
+			if live == nil {
+				if live, ok = <-clientCh; !ok {
+					return
+				}
+			}
+			if requestKey(live) != requestKey(step.Payload()) {
+				slog.Warn("Replay diverged", "connection", conn.id, "expected", requestKey(step.Payload()), "got", requestKey(live))
+			}
+			r.advance(step.Payload(), live)
+
+		case DirectionRobot:
+			if !r.waitFor(step.barrier, clientCh, &pending) {
+				return
+			}
+			if r.Speed > 0 {
+				delay := time.Duration(float64(step.Time.Sub(lastRecorded)) / r.Speed)
+				time.Sleep(time.Until(lastSent.Add(delay)))
+			}
+			if err := ws.WriteMessage(websocket.TextMessage, r.rewrite(step.Payload())); err != nil {
+				return
+			}
+		}
+		lastRecorded = step.Time
+		lastSent = time.Now()
+	}
+
+	// the recording ends here, keep the connection open until the client leaves
+	for range clientCh {
+	}
+}
+
+// claim reserves the first unused recorded connection opened with the same path and request.
+func (r *Replayer) claim(path string, first []byte) *recordedConn {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := requestKey(first)
+	for _, conn := range r.conns {
+		if conn.claimed || conn.path != path || len(conn.steps) == 0 || conn.steps[0].Direction != DirectionClient {
+			continue
+		}
+		if requestKey(conn.steps[0].Payload()) == key {
+			conn.claimed = true
+			return conn
+		}
+	}
+	return nil
+}
+
+// advance counts a received client message and learns the mission id it carries.
+func (r *Replayer) advance(recorded, live []byte) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if recordedID, liveID := missionID(recorded), missionID(live); recordedID != "" && liveID != "" && recordedID != liveID {
+		r.missionIDs[recordedID] = liveID
+	}
+
+	r.received++
+	close(r.progressCh)
+	r.progressCh = make(chan struct{})
+}
+
+// waitFor blocks until barrier client messages arrived, it reports false when the client left.
+// A client message arriving meanwhile is kept in pending for the next client step.
+func (r *Replayer) waitFor(barrier int, clientCh <-chan []byte, pending *[]byte) bool {
+	for {
+		r.mu.Lock()
+		received, progressCh := r.received, r.progressCh
+		r.mu.Unlock()
+
+		if received >= barrier {
+			return true
+		}
+
+		readCh := clientCh
+		if *pending != nil {
+			readCh = nil
+		}
+
+		select {
+		case <-progressCh:
+		case data, ok := <-readCh:
+			if !ok {
+				return false
+			}
+			*pending = data
+		}
+	}
+}
+
+// rewrite swaps recorded mission ids for the ones the live workflow generated.
+func (r *Replayer) rewrite(payload []byte) []byte {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for recordedID, liveID := range r.missionIDs {
+		payload = bytes.ReplaceAll(payload, []byte(recordedID), []byte(liveID))
+	}
+	return payload
+}
+
+type rosbridgeRequest struct {
+	Op      string `json:"op"`
+	Service string `json:"service"`
+	Topic   string `json:"topic"`
+	Args    struct {
+		Data interface{} `json:"data"`
+	} `json:"args"`
+}
+
+// requestKey identifies a client request by operation, target and robot api id.
+func requestKey(payload []byte) string {
+
+	var request rosbridgeRequest
+	if err := json.Unmarshal(payload, &request); err != nil {
+		return "invalid"
+	}
+
+	key := request.Op + " " + request.Service + request.Topic
+	if data, ok := request.Args.Data.(string); ok {
+		var args struct {
+			ApiID  int `json:"api_id"`
+			Action int `json:"action"`
+		}
+		if json.Unmarshal([]byte(data), &args) == nil && args.ApiID != 0 {
+			key += fmt.Sprintf(" api_id=%d", args.ApiID)
+			if args.Action != 0 {
+				key += fmt.Sprintf(" action=%d", args.Action)
+			}
+		}
+	}
+	return key
+}
+
+func missionID(payload []byte) string {
+
+	var request rosbridgeRequest
+	if err := json.Unmarshal(payload, &request); err != nil {
+		return ""
+	}
+
+	data, ok := request.Args.Data.(string)
+	if !ok {
+		return ""
+	}
+
+	var args struct {
+		MissionID string `json:"mission_id"`
+	}
+	if err := json.Unmarshal([]byte(data), &args); err != nil {
+		return ""
+	}
+	return args.MissionID
+}
@@ -0,0 +1,126 @@
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+type Direction string
+
+const (
+	DirectionOpen   Direction = "open"   // client connected, Data is empty
+	DirectionClient Direction = "client" // client to robot: call_service, subscribe, ...
+	DirectionRobot  Direction = "robot"  // robot to client: service_response, publish, ...
+	DirectionClose  Direction = "close"
+)
+
+// Message is one line of a session file. Conn numbers the websocket connections of the session.
+type Message struct {
+	Time      time.Time       `json:"time"`
+	Conn      int             `json:"conn"`
+	Path      string          `json:"path,omitempty"`
+	Direction Direction       `json:"direction"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Text      string          `json:"text,omitempty"` // payloads that are not valid JSON, kept verbatim
+}
+
+func newPayloadMessage(conn int, direction Direction, payload []byte) Message {
+	message := Message{Conn: conn, Direction: direction}
+	if json.Valid(payload) {
+		message.Data = json.RawMessage(payload)
+	} else {
+		message.Text = string(payload)
+	}
+	return message
+}
+
+// Payload returns the websocket message as it was sent.
+func (m Message) Payload() []byte {
+	if m.Data != nil {
+		return m.Data
+	}
+	return []byte(m.Text)
+}
+
+// Writer appends messages to a JSON lines session file.
+type Writer struct {
+	mu       sync.Mutex
+	file     *os.File
+	encoder  *json.Encoder
+	nextConn int
+}
+
+func NewWriter(path string) (*Writer, error) {
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetEscapeHTML(false)
+
+	return &Writer{file: file, encoder: encoder}, nil
+}
+
+// NewConn reserves the number of a new connection.
+func (w *Writer) NewConn() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.nextConn++
+	return w.nextConn
+}
+
+func (w *Writer) Write(message Message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	message.Time = time.Now()
+	return w.encoder.Encode(message)
+}
+
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+// Session is a recorded session loaded for replay.
+type Session struct {
+	Messages []Message
+}
+
+func LoadSession(path string) (*Session, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	session := &Session{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var message Message
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			return nil, fmt.Errorf("invalid session %s line %d: %v", path, line, err)
+		}
+		session.Messages = append(session.Messages, message)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(session.Messages) == 0 {
+		return nil, fmt.Errorf("session %s is empty", path)
+	}
+
+	return session, nil
+}
@@ -0,0 +1,142 @@
+package simulator
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+
+	"github.com/chungweeeei/Temporal-robot-project/pkg"
+)
+
+// SimulateBattery drains the battery while moving or standing and charges it while docked.
+// Sitting or lying down only drains a little.
+func (r *MockRobot) SimulateBattery() {
+
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		r.Mu.Lock()
+		switch {
+		case r.State.Charging:
+			r.State.BatteryLevel = math.Min(100, r.State.BatteryLevel+BatteryChargeRate)
+		case r.State.Mission.Code == MissionCodeStart:
+			r.State.BatteryLevel = math.Max(0, r.State.BatteryLevel-BatteryDrainMoving)
+		case r.State.Posture == PostureStandUp:
+			r.State.BatteryLevel = math.Max(0, r.State.BatteryLevel-BatteryDrainStanding)
+		default:
+			r.State.BatteryLevel = math.Max(0, r.State.BatteryLevel-BatteryDrainResting)
+		}
+		r.Mu.Unlock()
+	}
+}
+
+func (r *MockRobot) HandleChargeControl(service string, request []byte) pkg.RobotServiceResponse {
+
+	var chargeArgs struct {
+		ApiID  int `json:"api_id"`
+		Action int `json:"action"`
+	}
+
+	if err := json.Unmarshal(request, &chargeArgs); err != nil {
+		return newServiceResponse(service, BaseResponse{
+			ApiID: RobotChargeControlID,
+			Status: StatusDetail{
+				Code:    INVALID_INPUT,
+				Message: "Failed to unmarshaling charge control arguments, please check your input",
+			},
+		})
+	}
+
+	switch chargeArgs.Action {
+	case Dock:
+		return r.dock(service)
+	case Charge:
+		return r.charge(service)
+	default:
+		return newServiceResponse(service, BaseResponse{
+			ApiID: RobotChargeControlID,
+			Status: StatusDetail{
+				Code:    INVALID_INPUT,
+				Message: "Unknown charge control action",
+			},
+		})
+	}
+}
+
+func (r *MockRobot) dock(service string) pkg.RobotServiceResponse {
+
+	dock := r.dockPose()
+
+	r.Mu.Lock()
+	distance := math.Hypot(r.State.X-dock.X, r.State.Y-dock.Y)
+	moving := r.State.Mission.Code == MissionCodeStart
+	r.Mu.Unlock()
+
+	if moving || distance > DockRange {
+		return newServiceResponse(service, BaseResponse{
+			ApiID: RobotChargeControlID,
+			Status: StatusDetail{
+				Code:    REJECT,
+				Message: "Robot is not in front of the docking station",
+			},
+		})
+	}
+
+	// mock the final docking approach
+	time.Sleep(r.Timing.DockDelay)
+
+	r.Mu.Lock()
+	r.State.X = dock.X
+	r.State.Y = dock.Y
+	r.State.Orientation = normalizeAngle(dock.Orientation)
+	r.State.Docked = true
+	r.Mu.Unlock()
+
+	r.Logger.Info("Robot docked")
+
+	return newServiceResponse(service, BaseResponse{
+		ApiID: RobotChargeControlID,
+		Status: StatusDetail{
+			Code:    SUCCESS,
+			Message: "Dock command accepted",
+		},
+	})
+}
+
+func (r *MockRobot) charge(service string) pkg.RobotServiceResponse {
+
+	r.Mu.Lock()
+	defer r.Mu.Unlock()
+
+	if !r.State.Docked {
+		return newServiceResponse(service, BaseResponse{
+			ApiID: RobotChargeControlID,
+			Status: StatusDetail{
+				Code:    REJECT,
+				Message: "Robot must be docked before charging",
+			},
+		})
+	}
+
+	r.State.Charging = true
+	r.Logger.Info("Robot charging started")
+
+	return newServiceResponse(service, BaseResponse{
+		ApiID: RobotChargeControlID,
+		Status: StatusDetail{
+			Code:    SUCCESS,
+			Message: "Charge command accepted",
+		},
+	})
+}
+
+// dockPose is the map's docking landmark, or the default dock without a map.
+func (r *MockRobot) dockPose() Pose {
+	if r.Map != nil {
+		if dock, ok := r.Map.Dock(); ok {
+			return dock.Locate
+		}
+	}
+	return Pose{X: DockX, Y: DockY}
+}
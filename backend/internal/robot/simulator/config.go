@@ -20,6 +20,7 @@ const (
 	RobotStatusID        = 1009
 	RobotMotionControlID = 1013
 	RobotTTSCommandID    = 1014
+	RobotChargeControlID = 1015
 
 	// TODO: define stop action ID temporally for testing pause/resume feature
 	RobotStopActionID = 5000
@@ -31,6 +32,35 @@ const (
 	SitDown   = 6
 )
 
+type Posture string
+
+const (
+	PostureStandUp   Posture = "STAND_UP"
+	PostureStandDown Posture = "STAND_DOWN"
+	PostureSitDown   Posture = "SIT_DOWN"
+)
+
+// Charge control actions (RobotChargeControlID)
+const (
+	Dock   = 1
+	Charge = 2
+)
+
+// Battery simulation, in percent per second
+const (
+	BatteryDrainMoving   = 0.1
+	BatteryDrainStanding = 0.02
+	BatteryDrainResting  = 0.005
+	BatteryChargeRate    = 0.5
+)
+
+// Docking station pose and how close the robot must be to dock
+const (
+	DockX     = 0.0
+	DockY     = 0.0
+	DockRange = 1.0
+)
+
 type MissionCode int
 
 const (
@@ -0,0 +1,103 @@
+package simulator
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrRobotMoving = errors.New("robot is executing a mission, stop it first")
+
+// Snapshot returns a copy of the current robot state.
+func (r *MockRobot) Snapshot() RobotState {
+	r.Mu.Lock()
+	defer r.Mu.Unlock()
+	return r.State
+}
+
+// Teleport places the robot at pose without driving there, undocked.
+func (r *MockRobot) Teleport(pose Pose) error {
+
+	if r.Map != nil && r.Map.Occupied(pose.X, pose.Y) {
+		return fmt.Errorf("pose (%.2f, %.2f) is inside an obstacle", pose.X, pose.Y)
+	}
+
+	r.Mu.Lock()
+	defer r.Mu.Unlock()
+
+	if len(r.missions) > 0 {
+		return ErrRobotMoving
+	}
+
+	r.State.X = pose.X
+	r.State.Y = pose.Y
+	r.State.Orientation = normalizeAngle(pose.Orientation)
+	r.State.Docked = false
+	r.State.Charging = false
+
+	r.Logger.Info("Robot teleported", "x", pose.X, "y", pose.Y, "orientation", pose.Orientation)
+	return nil
+}
+
+// TeleportToDock places the robot docked on the docking station.
+func (r *MockRobot) TeleportToDock() error {
+
+	if err := r.Teleport(r.dockPose()); err != nil {
+		return err
+	}
+
+	r.Mu.Lock()
+	r.State.Docked = true
+	r.Mu.Unlock()
+
+	return nil
+}
+
+// TeleportToLandmark places the robot on a map landmark.
+func (r *MockRobot) TeleportToLandmark(name string) error {
+
+	if r.Map == nil {
+		return errors.New("no map is loaded")
+	}
+
+	landmark, ok := r.Map.Landmark(name)
+	if !ok {
+		return fmt.Errorf("unknown landmark %q", name)
+	}
+
+	return r.Teleport(landmark.Locate)
+}
+
+// SetBattery overrides the battery level, charging only sticks while docked.
+func (r *MockRobot) SetBattery(level float64, charging bool) error {
+
+	if level < 0 || level > 100 {
+		return errors.New("battery level must be between 0 and 100")
+	}
+
+	r.Mu.Lock()
+	defer r.Mu.Unlock()
+
+	if charging && !r.State.Docked {
+		return errors.New("robot must be docked to charge")
+	}
+
+	r.State.BatteryLevel = level
+	r.State.Charging = charging
+
+	r.Logger.Info("Battery level set", "battery_level", level)
+	return nil
+}
+
+// Reset stops any running mission, clears armed faults and restores the starting state.
+func (r *MockRobot) Reset() {
+
+	r.StopMission("")
+
+	r.Faults.Reset()
+
+	r.Mu.Lock()
+	r.State = r.initialState
+	r.Mu.Unlock()
+
+	r.Logger.Info("Robot state reset")
+}
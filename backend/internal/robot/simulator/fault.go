@@ -0,0 +1,151 @@
+package simulator
+
+import (
+	"sync"
+	"time"
+)
+
+// FaultInjector holds the faults currently armed against the simulated robot.
+// Scenarios arm faults, the robot and the websocket handler consume them.
+type FaultInjector struct {
+	mu            sync.Mutex
+	rejectMoves   int
+	abortArmed    bool
+	abortAfter    time.Duration
+	frozenUntil   time.Time
+	delay         time.Duration
+	delayUntil    time.Time
+	malformed     int
+	dropConnCh    chan struct{}
+	missionActive bool
+	missionStart  time.Time
+}
+
+func NewFaultInjector() *FaultInjector {
+	return &FaultInjector{
+		dropConnCh: make(chan struct{}),
+	}
+}
+
+// RejectNextMoves makes the next count Move commands fail with REJECT.
+func (f *FaultInjector) RejectNextMoves(count int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rejectMoves += count
+}
+
+func (f *FaultInjector) TakeRejectMove() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.rejectMoves == 0 {
+		return false
+	}
+	f.rejectMoves--
+	return true
+}
+
+// AbortMission aborts the running (or next) mission once it has run for after.
+func (f *FaultInjector) AbortMission(after time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.abortArmed = true
+	f.abortAfter = after
+}
+
+func (f *FaultInjector) missionStarted() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.missionActive = true
+	f.missionStart = time.Now()
+}
+
+func (f *FaultInjector) missionEnded() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.missionActive = false
+}
+
+// TakeAbort reports whether the running mission must be aborted now.
+func (f *FaultInjector) TakeAbort() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.abortArmed || !f.missionActive || time.Since(f.missionStart) < f.abortAfter {
+		return false
+	}
+	f.abortArmed = false
+	return true
+}
+
+// FreezeStatus stops status publishing for duration.
+func (f *FaultInjector) FreezeStatus(duration time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.frozenUntil = time.Now().Add(duration)
+}
+
+func (f *FaultInjector) StatusFrozen() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return time.Now().Before(f.frozenUntil)
+}
+
+// DelayResponses holds every service response back by delay for duration.
+func (f *FaultInjector) DelayResponses(delay, duration time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.delay = delay
+	f.delayUntil = time.Now().Add(duration)
+}
+
+func (f *FaultInjector) ResponseDelay() time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if time.Now().After(f.delayUntil) {
+		return 0
+	}
+	return f.delay
+}
+
+// MalformedMessages makes the next count outgoing messages invalid JSON.
+func (f *FaultInjector) MalformedMessages(count int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.malformed += count
+}
+
+func (f *FaultInjector) TakeMalformed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.malformed == 0 {
+		return false
+	}
+	f.malformed--
+	return true
+}
+
+// DropConnections closes every open websocket connection.
+func (f *FaultInjector) DropConnections() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	close(f.dropConnCh)
+	f.dropConnCh = make(chan struct{})
+}
+
+// Dropped is closed on the next DropConnections call.
+func (f *FaultInjector) Dropped() <-chan struct{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.dropConnCh
+}
+
+// Reset disarms every pending fault, open connections are kept.
+func (f *FaultInjector) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rejectMoves = 0
+	f.abortArmed = false
+	f.frozenUntil = time.Time{}
+	f.delay = 0
+	f.delayUntil = time.Time{}
+	f.malformed = 0
+}
@@ -0,0 +1,116 @@
+package simulator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RobotConfig describes one simulated robot of a fleet.
+type RobotConfig struct {
+	Name     string   `yaml:"name"`
+	Port     int      `yaml:"port"`     // optional dedicated port, always served under /robots/{name}
+	Landmark string   `yaml:"landmark"` // start at a map landmark
+	Pose     *Pose    `yaml:"pose"`     // or at an explicit pose
+	Battery  *float64 `yaml:"battery"`
+	Scenario string   `yaml:"scenario"` // optional fault scenario for this robot only, relative to the fleet file
+}
+
+type FleetConfig struct {
+	Robots []RobotConfig `yaml:"robots"`
+}
+
+func LoadFleet(path string) (*FleetConfig, error) {
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fleet FleetConfig
+	if err := yaml.Unmarshal(raw, &fleet); err != nil {
+		return nil, fmt.Errorf("invalid fleet %s: %v", path, err)
+	}
+
+	if err := fleet.validate(); err != nil {
+		return nil, fmt.Errorf("invalid fleet %s: %v", path, err)
+	}
+
+	for i, robot := range fleet.Robots {
+		if robot.Scenario != "" && !filepath.IsAbs(robot.Scenario) {
+			fleet.Robots[i].Scenario = filepath.Join(filepath.Dir(path), robot.Scenario)
+		}
+	}
+
+	return &fleet, nil
+}
+
+// NewFleetConfig names count robots robot-1 .. robot-N, all starting with the default options.
+func NewFleetConfig(count int) *FleetConfig {
+	fleet := &FleetConfig{}
+	for i := 1; i <= count; i++ {
+		fleet.Robots = append(fleet.Robots, RobotConfig{Name: fmt.Sprintf("robot-%d", i)})
+	}
+	return fleet
+}
+
+func (f *FleetConfig) validate() error {
+
+	if len(f.Robots) == 0 {
+		return fmt.Errorf("at least one robot is required")
+	}
+
+	names := map[string]bool{}
+	ports := map[int]bool{}
+	for _, robot := range f.Robots {
+		if robot.Name == "" {
+			return fmt.Errorf("every robot needs a name")
+		}
+		if names[robot.Name] {
+			return fmt.Errorf("duplicated robot name %q", robot.Name)
+		}
+		names[robot.Name] = true
+
+		if robot.Port != 0 {
+			if ports[robot.Port] {
+				return fmt.Errorf("duplicated port %d", robot.Port)
+			}
+			ports[robot.Port] = true
+		}
+		if robot.Battery != nil && (*robot.Battery < 0 || *robot.Battery > 100) {
+			return fmt.Errorf("robot %q battery must be between 0 and 100", robot.Name)
+		}
+	}
+
+	return nil
+}
+
+// Options derives the robot's options from the shared base options.
+func (c RobotConfig) Options(base Options) (Options, error) {
+
+	opts := base
+	opts.Name = c.Name
+
+	if c.Battery != nil {
+		opts.BatteryLevel = *c.Battery
+	}
+
+	switch {
+	case c.Pose != nil:
+		opts.StartPose = c.Pose
+	case c.Landmark != "":
+		if base.Map == nil {
+			return opts, fmt.Errorf("robot %q starts at landmark %q but no map is loaded", c.Name, c.Landmark)
+		}
+		landmark, ok := base.Map.Landmark(c.Landmark)
+		if !ok {
+			return opts, fmt.Errorf("robot %q starts at unknown landmark %q", c.Name, c.Landmark)
+		}
+		pose := landmark.Locate
+		opts.StartPose = &pose
+	}
+
+	return opts, nil
+}
@@ -3,6 +3,7 @@ package simulator
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/chungweeeei/Temporal-robot-project/pkg"
@@ -19,7 +20,7 @@ func (r *MockRobot) MoveToLocation(service string, request []byte) pkg.RobotServ
 	}
 	err := json.Unmarshal(request, &moveArgs)
 	if err != nil {
-		r.ErrorLog.Println("Error unmarshaling move args:", err)
+		r.Logger.Error("Error unmarshaling move args", "error", err)
 		respData := BaseResponse{
 			ApiID: RobotMoveCommandID,
 			Status: StatusDetail{
@@ -39,8 +40,33 @@ func (r *MockRobot) MoveToLocation(service string, request []byte) pkg.RobotServ
 		}
 	}
 
+	if r.Faults.TakeRejectMove() {
+		r.Logger.Info("Move command rejected by injected fault")
+		return newServiceResponse(service, BaseResponse{
+			ApiID: RobotMoveCommandID,
+			Status: StatusDetail{
+				Code:    REJECT,
+				Message: "Move command rejected",
+			},
+		})
+	}
+
+	r.Mu.Lock()
+	posture := r.State.Posture
+	r.Mu.Unlock()
+	if posture == PostureSitDown {
+		return newServiceResponse(service, BaseResponse{
+			ApiID: RobotMoveCommandID,
+			Status: StatusDetail{
+				Code:    REJECT,
+				Message: "Can not move while sitting, stand up first",
+			},
+		})
+	}
+
 	// starting goroutine move to target point
-	go r.Move(moveArgs.MissionID, moveArgs.X, moveArgs.Y)
+	// orientation is sent in radian, the simulator state keeps degree
+	go r.Move(moveArgs.MissionID, moveArgs.X, moveArgs.Y, moveArgs.Orientation*(180.0/math.Pi))
 
 	respData := BaseResponse{
 		ApiID: RobotMoveCommandID,
@@ -62,29 +88,44 @@ func (r *MockRobot) MoveToLocation(service string, request []byte) pkg.RobotServ
 	}
 }
 
+// HandleStopCommand stops the mission named by mission_id, or every mission when it is empty.
 func (r *MockRobot) HandleStopCommand(service string, request []byte) pkg.RobotServiceResponse {
 
-	// send stop signal to robot
-	r.StopChan <- true
+	var stopArgs struct {
+		ApiID     int    `json:"api_id"`
+		MissionID string `json:"mission_id"`
+	}
+	if err := json.Unmarshal(request, &stopArgs); err != nil {
+		return newServiceResponse(service, BaseResponse{
+			ApiID: RobotStopActionID,
+			Status: StatusDetail{
+				Code:    INVALID_INPUT,
+				Message: "Failed to unmarshaling stop arguments, please check your input",
+			},
+		})
+	}
 
-	// return success response
-	respData := BaseResponse{
+	if stopped := r.StopMission(stopArgs.MissionID); stopped == 0 {
+		message := "Nothing to stop"
+		if stopArgs.MissionID != "" {
+			message = fmt.Sprintf("Mission %s is not running, nothing to stop", stopArgs.MissionID)
+		}
+		return newServiceResponse(service, BaseResponse{
+			ApiID: RobotStopActionID,
+			Status: StatusDetail{
+				Code:    NOT_EXIST,
+				Message: message,
+			},
+		})
+	}
+
+	return newServiceResponse(service, BaseResponse{
 		ApiID: RobotStopActionID,
 		Status: StatusDetail{
 			Code:    SUCCESS,
 			Message: "Stop command accepted",
 		},
-	}
-	bytes, _ := json.Marshal(respData)
-	return pkg.RobotServiceResponse{
-		Op:      "service_response",
-		Service: service,
-		Values: struct {
-			Data string `json:"data"`
-		}{
-			Data: string(bytes),
-		},
-	}
+	})
 }
 
 func (r *MockRobot) HandleMotionControl(service string, request []byte) pkg.RobotServiceResponse {
@@ -115,8 +156,43 @@ func (r *MockRobot) HandleMotionControl(service string, request []byte) pkg.Robo
 		}
 	}
 
-	// mock 2 seconds delay for motion control execution
-	time.Sleep(2 * time.Second)
+	var posture Posture
+	switch motionArgs.Action {
+	case StandUp:
+		posture = PostureStandUp
+	case StandDown:
+		posture = PostureStandDown
+	case SitDown:
+		posture = PostureSitDown
+	default:
+		return newServiceResponse(service, BaseResponse{
+			ApiID: RobotMotionControlID,
+			Status: StatusDetail{
+				Code:    INVALID_INPUT,
+				Message: fmt.Sprintf("Unknown motion control action: %d", motionArgs.Action),
+			},
+		})
+	}
+
+	r.Mu.Lock()
+	moving := r.State.Mission.Code == MissionCodeStart
+	r.Mu.Unlock()
+	if moving {
+		return newServiceResponse(service, BaseResponse{
+			ApiID: RobotMotionControlID,
+			Status: StatusDetail{
+				Code:    REJECT,
+				Message: "Can not change posture while moving",
+			},
+		})
+	}
+
+	// mock motion control execution
+	time.Sleep(r.Timing.MotionControlDelay)
+
+	r.Mu.Lock()
+	r.State.Posture = posture
+	r.Mu.Unlock()
 
 	respData := BaseResponse{
 		ApiID: RobotMotionControlID,
@@ -167,8 +243,8 @@ func (r *MockRobot) HandleTTSCommand(service string, request []byte) pkg.RobotSe
 		}
 	}
 
-	// mock 2 seconds delay for motion control execution
-	time.Sleep(2 * time.Second)
+	// mock speaking, longer text takes longer
+	time.Sleep(r.Timing.TTSDuration(ttsArgs.Text))
 
 	respData := BaseResponse{
 		ApiID: RobotTTSCommandID,
@@ -192,7 +268,7 @@ func (r *MockRobot) HandleTTSCommand(service string, request []byte) pkg.RobotSe
 func (r *MockRobot) HandleHeadAngle(service string, request []byte) pkg.RobotServiceResponse {
 
 	// mock dealing with head angle setting
-	time.Sleep(2 * time.Second)
+	time.Sleep(r.Timing.HeadDelay)
 
 	respData := StatusDetail{
 		Code:    SUCCESS,
@@ -211,7 +287,7 @@ func (r *MockRobot) HandleHeadAngle(service string, request []byte) pkg.RobotSer
 }
 
 func (r *MockRobot) HandleUnknownRequest(unknownId int, service string) pkg.RobotServiceResponse {
-	r.ErrorLog.Println("Received Unknown API ID:", unknownId)
+	r.Logger.Error("Received unknown API ID", "api_id", unknownId)
 	respData := BaseResponse{
 		ApiID: unknownId,
 		Status: StatusDetail{
@@ -232,7 +308,7 @@ func (r *MockRobot) HandleUnknownRequest(unknownId int, service string) pkg.Robo
 }
 
 func (r *MockRobot) HandleUnknownService(service string) pkg.RobotServiceResponse {
-	r.ErrorLog.Println("Received Unknown Service:", service)
+	r.Logger.Error("Received unknown service", "service", service)
 	respData := BaseResponse{
 		ApiID: 0,
 		Status: StatusDetail{
@@ -254,11 +330,17 @@ func (r *MockRobot) HandleUnknownService(service string) pkg.RobotServiceRespons
 
 func (r *MockRobot) GetRobotStatus() pkg.RobotTopicResponse {
 
+	r.Mu.Lock()
+	defer r.Mu.Unlock()
+
 	qx, qy, qz, qw := transferOrientationToQuaternion(r.State.Orientation)
 
 	robotStatus := RobotStatus{
 		ApiID:        0,
-		BatteryLevel: r.State.BatteryLevel,
+		BatteryLevel: int(math.Round(r.State.BatteryLevel)),
+		Charging:     r.State.Charging,
+		Docked:       r.State.Docked,
+		Posture:      r.State.Posture,
 		Pose: struct {
 			Orientation struct {
 				X float64 `json:"x"`
@@ -308,7 +390,7 @@ func (r *MockRobot) GetRobotStatus() pkg.RobotTopicResponse {
 		DeviceName   string      `json:"device_name"`
 		DeviceStatus RobotStatus `json:"device_status"`
 	}{
-		DeviceName:   "MockRobot",
+		DeviceName:   r.Name,
 		DeviceStatus: robotStatus,
 	}
 
@@ -1,6 +1,11 @@
 package simulator
 
-import "math"
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/chungweeeei/Temporal-robot-project/pkg"
+)
 
 func transferOrientationToQuaternion(orientation float64) (qx, qy, qz, qw float64) {
 	// Assuming orientation is in degrees and represents rotation around Z-axis (Yaw)
@@ -14,3 +19,16 @@ func transferOrientationToQuaternion(orientation float64) (qx, qy, qz, qw float6
 
 	return qx, qy, qz, qw
 }
+
+func newServiceResponse(service string, respData interface{}) pkg.RobotServiceResponse {
+	bytes, _ := json.Marshal(respData)
+	return pkg.RobotServiceResponse{
+		Op:      "service_response",
+		Service: service,
+		Values: struct {
+			Data string `json:"data"`
+		}{
+			Data: string(bytes),
+		},
+	}
+}
@@ -0,0 +1,107 @@
+package simulator
+
+import (
+	"context"
+	"time"
+)
+
+// MissionInfo describes a move the robot is executing.
+type MissionInfo struct {
+	ID      string    `json:"mission_id"`
+	Target  Pose      `json:"target"`
+	Started time.Time `json:"started"`
+}
+
+// mission is a running move, cancelling its context stops the robot.
+type mission struct {
+	info   MissionInfo
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// startMission registers a new move. Like the real robot, a new goal preempts the running one:
+// the previous mission is cancelled and has ended by the time startMission returns.
+func (r *MockRobot) startMission(info MissionInfo) *mission {
+
+	// one preemption at a time, two goals arriving together must not both start
+	r.startMu.Lock()
+	defer r.startMu.Unlock()
+
+	r.Mu.Lock()
+	running := make([]*mission, 0, len(r.missions))
+	for _, m := range r.missions {
+		running = append(running, m)
+	}
+	r.Mu.Unlock()
+
+	for _, m := range running {
+		r.Logger.Info("Mission preempted", "mission_id", m.info.ID, "by_mission_id", info.ID)
+		m.cancel()
+		<-m.done
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &mission{info: info, ctx: ctx, cancel: cancel, done: make(chan struct{})}
+
+	r.Mu.Lock()
+	r.missions[info.ID] = m
+	r.Mu.Unlock()
+
+	return m
+}
+
+func (r *MockRobot) endMission(m *mission) {
+	r.Mu.Lock()
+	if r.missions[m.info.ID] == m {
+		delete(r.missions, m.info.ID)
+	}
+	r.Mu.Unlock()
+
+	m.cancel()
+	close(m.done)
+}
+
+// StopMission stops the mission with the given id, or every mission when id is empty.
+// It waits for the stopped missions to end and reports how many were running.
+func (r *MockRobot) StopMission(id string) int {
+
+	r.Mu.Lock()
+	stopping := []*mission{}
+	for missionID, m := range r.missions {
+		if id == "" || missionID == id {
+			stopping = append(stopping, m)
+		}
+	}
+	r.Mu.Unlock()
+
+	for _, m := range stopping {
+		m.cancel()
+		<-m.done
+	}
+
+	return len(stopping)
+}
+
+// ActiveMissions lists the moves currently running.
+func (r *MockRobot) ActiveMissions() []MissionInfo {
+	r.Mu.Lock()
+	defer r.Mu.Unlock()
+
+	missions := make([]MissionInfo, 0, len(r.missions))
+	for _, m := range r.missions {
+		missions = append(missions, m.info)
+	}
+	return missions
+}
+
+// setMissionResult updates the published mission status, unless a newer mission took over.
+func (r *MockRobot) setMissionResult(m *mission, code MissionCode, message string) {
+	r.Mu.Lock()
+	defer r.Mu.Unlock()
+	if r.State.MissionID != m.info.ID {
+		return
+	}
+	r.State.Mission.Code = code
+	r.State.Mission.Message = message
+}
@@ -1,52 +1,107 @@
 package simulator
 
 import (
-	"log"
-	"os"
+	"log/slog"
 	"sync"
 )
 
 type RobotState struct {
-	BatteryLevel int
+	BatteryLevel float64 `json:"battery_level"`
+	Docked       bool    `json:"docked"`
+	Charging     bool    `json:"charging"`
+	Posture      Posture `json:"posture"`
 	// [New]
-	X           float64
-	Y           float64
-	Orientation float64
+	X           float64 `json:"x"`
+	Y           float64 `json:"y"`
+	Orientation float64 `json:"orientation"`
 	// [New]
-	MissionID string
+	MissionID string `json:"mission_id"`
 	Mission   struct {
-		Code    MissionCode
-		Message string
-	}
+		Code    MissionCode `json:"code"`
+		Message string      `json:"message"`
+	} `json:"mission"`
 }
 type MockRobot struct {
-	Mu       sync.Mutex
-	State    RobotState
-	InfoLog  *log.Logger
-	ErrorLog *log.Logger
-	StopChan chan bool
+	Name       string
+	Mu         sync.Mutex
+	State      RobotState
+	Kinematics Kinematics
+	Timing     Timing
+	Faults     *FaultInjector
+	Map        *NavMap
+	Logger     *slog.Logger
+
+	startMu      sync.Mutex
+	missions     map[string]*mission // running moves, guarded by Mu
+	initialState RobotState
+}
+
+const DefaultRobotName = "MockRobot"
+
+// Options configure the simulated robot's identity, starting state, physics, response timing and map.
+type Options struct {
+	Name         string
+	StartPose    *Pose // falls back to the map's initial pose, then the origin
+	BatteryLevel float64
+	Kinematics   Kinematics
+	Timing       Timing
+	Map          *NavMap
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Name:         DefaultRobotName,
+		BatteryLevel: 30,
+		Kinematics:   DefaultKinematics(),
+		Timing:       DefaultTiming(),
+	}
 }
 
 func NewMockRobot() *MockRobot {
+	return NewMockRobotWithOptions(DefaultOptions())
+}
+
+func NewMockRobotWithOptions(opts Options) *MockRobot {
 	mockRobot := &MockRobot{
-		Mu: sync.Mutex{},
+		Name: opts.Name,
+		Mu:   sync.Mutex{},
 		State: RobotState{
-			BatteryLevel: 30,
+			BatteryLevel: opts.BatteryLevel,
+			Posture:      PostureStandUp,
 			X:            0.0,
 			Y:            0.0,
 			Orientation:  0.0,
 			MissionID:    "0",
 			Mission: struct {
-				Code    MissionCode
-				Message string
+				Code    MissionCode `json:"code"`
+				Message string      `json:"message"`
 			}{
 				Code:    MissionCodeInit,
 				Message: "INIT",
 			},
 		},
-		InfoLog:  log.New(os.Stdout, "[INFO]\t", log.Ldate|log.Ltime),
-		ErrorLog: log.New(os.Stdout, "[ERROR]\t", log.Ldate|log.Ltime|log.Lshortfile),
-		StopChan: make(chan bool),
+		Kinematics: opts.Kinematics,
+		Timing:     opts.Timing,
+		Faults:     NewFaultInjector(),
+		Map:        opts.Map,
+		Logger:     slog.With("robot_id", opts.Name), // tells fleet robots apart in the shared log
+		missions:   map[string]*mission{},
+	}
+
+	// start at the configured pose, or the map's initial pose
+	startPose := opts.StartPose
+	if startPose == nil && opts.Map != nil {
+		startPose = opts.Map.InitialPose
 	}
+	if startPose != nil {
+		mockRobot.State.X = startPose.X
+		mockRobot.State.Y = startPose.Y
+		mockRobot.State.Orientation = normalizeAngle(startPose.Orientation)
+	}
+
+	mockRobot.initialState = mockRobot.State
+
+	go mockRobot.SimulateBattery()
+
 	return mockRobot
 }
@@ -0,0 +1,252 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Pose struct {
+	X           float64 `yaml:"x" json:"x"`
+	Y           float64 `yaml:"y" json:"y"`
+	Orientation float64 `yaml:"orientation" json:"orientation"` // degree
+}
+
+// Landmark follows the sema.yaml format, type 1 marks the docking station.
+type Landmark struct {
+	Name   string `yaml:"name" json:"name"`
+	Type   int    `yaml:"type" json:"type"`
+	Locate Pose   `yaml:"locate" json:"locate"`
+}
+
+const LandmarkTypeDock = 1
+
+type landmarkFile struct {
+	InitialPose *Pose      `yaml:"initial_pose"`
+	Landmarks   []Landmark `yaml:"landmarks"`
+}
+
+type Obstacle struct {
+	Name    string      `yaml:"name"`
+	Polygon [][]float64 `yaml:"polygon"` // [[x, y], ...] in meter
+}
+
+// OccupancyGrid marks occupied cells with '#'. Row 0 starts at Origin.Y and grows along +y.
+type OccupancyGrid struct {
+	Origin     Pose     `yaml:"origin"`
+	Resolution float64  `yaml:"resolution"`
+	Rows       []string `yaml:"rows"`
+}
+
+type mapFile struct {
+	Landmarks   string         `yaml:"landmarks"` // sema.yaml path, relative to the map file
+	InitialPose *Pose          `yaml:"initial_pose"`
+	Resolution  float64        `yaml:"resolution"`
+	RobotRadius float64        `yaml:"robot_radius"`
+	Obstacles   []Obstacle     `yaml:"obstacles"`
+	Grid        *OccupancyGrid `yaml:"grid"`
+}
+
+// NavMap is the geometry simulated moves are planned against.
+type NavMap struct {
+	InitialPose *Pose
+	Landmarks   []Landmark
+	Obstacles   []Obstacle
+	Grid        *OccupancyGrid
+	Resolution  float64
+	RobotRadius float64
+}
+
+func LoadMap(path string) (*NavMap, error) {
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file mapFile
+	if err := yaml.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("invalid map %s: %v", path, err)
+	}
+
+	navMap := &NavMap{
+		InitialPose: file.InitialPose,
+		Obstacles:   file.Obstacles,
+		Grid:        file.Grid,
+		Resolution:  file.Resolution,
+		RobotRadius: file.RobotRadius,
+	}
+	if navMap.Resolution <= 0 {
+		navMap.Resolution = 0.1
+	}
+	if navMap.RobotRadius < 0 {
+		return nil, fmt.Errorf("invalid map %s: robot_radius must not be negative", path)
+	}
+
+	for _, obstacle := range file.Obstacles {
+		if len(obstacle.Polygon) < 3 {
+			return nil, fmt.Errorf("invalid map %s: obstacle %q needs at least 3 points", path, obstacle.Name)
+		}
+		for _, point := range obstacle.Polygon {
+			if len(point) != 2 {
+				return nil, fmt.Errorf("invalid map %s: obstacle %q points must be [x, y]", path, obstacle.Name)
+			}
+		}
+	}
+
+	if file.Grid != nil && file.Grid.Resolution <= 0 {
+		return nil, fmt.Errorf("invalid map %s: grid resolution must be positive", path)
+	}
+
+	if file.Landmarks != "" {
+		landmarkPath := file.Landmarks
+		if !filepath.IsAbs(landmarkPath) {
+			landmarkPath = filepath.Join(filepath.Dir(path), landmarkPath)
+		}
+		landmarks, err := loadLandmarks(landmarkPath)
+		if err != nil {
+			return nil, err
+		}
+		navMap.Landmarks = landmarks.Landmarks
+		if navMap.InitialPose == nil {
+			navMap.InitialPose = landmarks.InitialPose
+		}
+	}
+
+	return navMap, nil
+}
+
+func loadLandmarks(path string) (*landmarkFile, error) {
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file landmarkFile
+	if err := yaml.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("invalid landmarks %s: %v", path, err)
+	}
+
+	return &file, nil
+}
+
+func (m *NavMap) Landmark(name string) (Landmark, bool) {
+	for _, landmark := range m.Landmarks {
+		if landmark.Name == name {
+			return landmark, true
+		}
+	}
+	return Landmark{}, false
+}
+
+// Dock returns the first docking station landmark.
+func (m *NavMap) Dock() (Landmark, bool) {
+	for _, landmark := range m.Landmarks {
+		if landmark.Type == LandmarkTypeDock {
+			return landmark, true
+		}
+	}
+	return Landmark{}, false
+}
+
+// Occupied reports whether the robot footprint centered at (x, y) touches an obstacle.
+func (m *NavMap) Occupied(x, y float64) bool {
+
+	for _, obstacle := range m.Obstacles {
+		if pointInPolygon(x, y, obstacle.Polygon) || distanceToPolygon(x, y, obstacle.Polygon) < m.RobotRadius {
+			return true
+		}
+	}
+
+	if m.Grid != nil {
+		// check every grid cell under the footprint
+		reach := int(math.Ceil(m.RobotRadius / m.Grid.Resolution))
+		col := int(math.Floor((x - m.Grid.Origin.X) / m.Grid.Resolution))
+		row := int(math.Floor((y - m.Grid.Origin.Y) / m.Grid.Resolution))
+		for r := row - reach; r <= row+reach; r++ {
+			for c := col - reach; c <= col+reach; c++ {
+				if !m.Grid.occupied(r, c) {
+					continue
+				}
+				cx := m.Grid.Origin.X + (float64(c)+0.5)*m.Grid.Resolution
+				cy := m.Grid.Origin.Y + (float64(r)+0.5)*m.Grid.Resolution
+				if math.Hypot(cx-x, cy-y) <= m.RobotRadius+m.Grid.Resolution/2 {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+func (g *OccupancyGrid) occupied(row, col int) bool {
+	if row < 0 || row >= len(g.Rows) || col < 0 || col >= len(g.Rows[row]) {
+		return false
+	}
+	return g.Rows[row][col] == '#'
+}
+
+// bounds returns the area covered by the map geometry.
+func (m *NavMap) bounds() (minX, minY, maxX, maxY float64) {
+
+	minX, minY = math.Inf(1), math.Inf(1)
+	maxX, maxY = math.Inf(-1), math.Inf(-1)
+	extend := func(x, y float64) {
+		minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+		minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+	}
+
+	for _, landmark := range m.Landmarks {
+		extend(landmark.Locate.X, landmark.Locate.Y)
+	}
+	for _, obstacle := range m.Obstacles {
+		for _, point := range obstacle.Polygon {
+			extend(point[0], point[1])
+		}
+	}
+	if m.Grid != nil {
+		extend(m.Grid.Origin.X, m.Grid.Origin.Y)
+		width := 0
+		for _, row := range m.Grid.Rows {
+			width = max(width, len(row))
+		}
+		extend(m.Grid.Origin.X+float64(width)*m.Grid.Resolution, m.Grid.Origin.Y+float64(len(m.Grid.Rows))*m.Grid.Resolution)
+	}
+
+	return minX, minY, maxX, maxY
+}
+
+func pointInPolygon(x, y float64, polygon [][]float64) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		xi, yi := polygon[i][0], polygon[i][1]
+		xj, yj := polygon[j][0], polygon[j][1]
+		if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func distanceToPolygon(x, y float64, polygon [][]float64) float64 {
+	distance := math.Inf(1)
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		distance = math.Min(distance, distanceToSegment(x, y, polygon[j][0], polygon[j][1], polygon[i][0], polygon[i][1]))
+	}
+	return distance
+}
+
+func distanceToSegment(px, py, ax, ay, bx, by float64) float64 {
+	dx, dy := bx-ax, by-ay
+	lengthSq := dx*dx + dy*dy
+	if lengthSq == 0 {
+		return math.Hypot(px-ax, py-ay)
+	}
+	t := math.Max(0, math.Min(1, ((px-ax)*dx+(py-ay)*dy)/lengthSq))
+	return math.Hypot(px-(ax+t*dx), py-(ay+t*dy))
+}
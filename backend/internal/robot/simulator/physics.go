@@ -0,0 +1,69 @@
+package simulator
+
+import (
+	"math"
+	"time"
+)
+
+// Kinematics describes how fast the simulated robot moves.
+type Kinematics struct {
+	LinearSpeed  float64       // maximum linear speed (m/s)
+	AngularSpeed float64       // rotation speed (degree/s)
+	Acceleration float64       // linear acceleration and deceleration (m/s^2)
+	UpdateRate   time.Duration // pose update interval
+	Tolerance    float64       // distance (m) considered as arrived
+}
+
+// Timing replaces the fixed delays of the non-motion commands.
+type Timing struct {
+	MotionControlDelay time.Duration
+	HeadDelay          time.Duration
+	DockDelay          time.Duration
+	TTSBaseDelay       time.Duration
+	TTSPerCharacter    time.Duration // TTS duration grows with the text length
+}
+
+func DefaultKinematics() Kinematics {
+	return Kinematics{
+		LinearSpeed:  0.5,
+		AngularSpeed: 45,
+		Acceleration: 0.25,
+		UpdateRate:   100 * time.Millisecond,
+		Tolerance:    0.05,
+	}
+}
+
+func DefaultTiming() Timing {
+	return Timing{
+		MotionControlDelay: 2 * time.Second,
+		HeadDelay:          2 * time.Second,
+		DockDelay:          2 * time.Second,
+		TTSBaseDelay:       500 * time.Millisecond,
+		TTSPerCharacter:    60 * time.Millisecond,
+	}
+}
+
+func (t Timing) TTSDuration(text string) time.Duration {
+	return t.TTSBaseDelay + time.Duration(len([]rune(text)))*t.TTSPerCharacter
+}
+
+// normalizeAngle wraps a degree angle into (-180, 180].
+func normalizeAngle(angle float64) float64 {
+	angle = math.Mod(angle, 360)
+	if angle > 180 {
+		angle -= 360
+	} else if angle <= -180 {
+		angle += 360
+	}
+	return angle
+}
+
+// nextSpeed follows a trapezoidal profile: accelerate up to the max speed and
+// decelerate in time to stop at the target.
+func (k Kinematics) nextSpeed(speed, remaining float64) float64 {
+	dt := k.UpdateRate.Seconds()
+	speed = math.Min(speed+k.Acceleration*dt, k.LinearSpeed)
+	speed = math.Min(speed, math.Sqrt(2*k.Acceleration*remaining))
+	// never stall right before the target
+	return math.Max(speed, k.Acceleration*dt)
+}
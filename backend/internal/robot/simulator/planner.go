@@ -0,0 +1,201 @@
+package simulator
+
+import (
+	"container/heap"
+	"math"
+)
+
+type Point struct {
+	X float64
+	Y float64
+}
+
+// planner margin around the map geometry, in meter
+const plannerMargin = 2.0
+
+type gridCell struct {
+	row, col int
+}
+
+type plannerGrid struct {
+	minX, minY float64
+	resolution float64
+	rows, cols int
+	blocked    []bool
+}
+
+func (m *NavMap) newPlannerGrid(start, goal Point) *plannerGrid {
+
+	minX, minY, maxX, maxY := m.bounds()
+	minX = math.Min(minX, math.Min(start.X, goal.X)) - plannerMargin
+	minY = math.Min(minY, math.Min(start.Y, goal.Y)) - plannerMargin
+	maxX = math.Max(maxX, math.Max(start.X, goal.X)) + plannerMargin
+	maxY = math.Max(maxY, math.Max(start.Y, goal.Y)) + plannerMargin
+
+	grid := &plannerGrid{
+		minX:       minX,
+		minY:       minY,
+		resolution: m.Resolution,
+		cols:       int(math.Ceil((maxX-minX)/m.Resolution)) + 1,
+		rows:       int(math.Ceil((maxY-minY)/m.Resolution)) + 1,
+	}
+	grid.blocked = make([]bool, grid.rows*grid.cols)
+	for row := 0; row < grid.rows; row++ {
+		for col := 0; col < grid.cols; col++ {
+			center := grid.center(gridCell{row, col})
+			grid.blocked[row*grid.cols+col] = m.Occupied(center.X, center.Y)
+		}
+	}
+
+	return grid
+}
+
+func (g *plannerGrid) cell(p Point) gridCell {
+	return gridCell{
+		row: int(math.Round((p.Y - g.minY) / g.resolution)),
+		col: int(math.Round((p.X - g.minX) / g.resolution)),
+	}
+}
+
+func (g *plannerGrid) center(c gridCell) Point {
+	return Point{X: g.minX + float64(c.col)*g.resolution, Y: g.minY + float64(c.row)*g.resolution}
+}
+
+func (g *plannerGrid) free(c gridCell) bool {
+	return c.row >= 0 && c.row < g.rows && c.col >= 0 && c.col < g.cols && !g.blocked[c.row*g.cols+c.col]
+}
+
+// PlanPath returns waypoints from start to goal around obstacles (start excluded, goal included).
+// It reports false when the goal can not be reached.
+func (m *NavMap) PlanPath(start, goal Point) ([]Point, bool) {
+
+	if m.segmentFree(start, goal) {
+		return []Point{goal}, true
+	}
+
+	grid := m.newPlannerGrid(start, goal)
+	startCell, goalCell := grid.cell(start), grid.cell(goal)
+	if !grid.free(goalCell) {
+		return nil, false
+	}
+
+	cells, ok := grid.aStar(startCell, goalCell)
+	if !ok {
+		return nil, false
+	}
+
+	path := make([]Point, 0, len(cells)+1)
+	for _, c := range cells[1:] {
+		path = append(path, grid.center(c))
+	}
+	path = append(path, goal)
+
+	return m.smoothPath(start, path), true
+}
+
+// FreeDistance returns how far the robot can drive from start toward goal before hitting an obstacle.
+func (m *NavMap) FreeDistance(start, goal Point) float64 {
+
+	length := math.Hypot(goal.X-start.X, goal.Y-start.Y)
+	step := m.Resolution / 2
+	for travelled := step; travelled < length; travelled += step {
+		t := travelled / length
+		if m.Occupied(start.X+(goal.X-start.X)*t, start.Y+(goal.Y-start.Y)*t) {
+			return math.Max(0, travelled-step)
+		}
+	}
+	return length
+}
+
+func (m *NavMap) segmentFree(a, b Point) bool {
+	return m.FreeDistance(a, b) >= math.Hypot(b.X-a.X, b.Y-a.Y)
+}
+
+// smoothPath drops waypoints that have a clear line of sight past them.
+func (m *NavMap) smoothPath(start Point, path []Point) []Point {
+
+	smoothed := []Point{}
+	from := start
+	for i := 0; i < len(path); {
+		next := i
+		for j := len(path) - 1; j > i; j-- {
+			if m.segmentFree(from, path[j]) {
+				next = j
+				break
+			}
+		}
+		smoothed = append(smoothed, path[next])
+		from = path[next]
+		i = next + 1
+	}
+
+	return smoothed
+}
+
+func (g *plannerGrid) aStar(start, goal gridCell) ([]gridCell, bool) {
+
+	heuristic := func(c gridCell) float64 {
+		dr, dc := math.Abs(float64(c.row-goal.row)), math.Abs(float64(c.col-goal.col))
+		return (dr + dc) + (math.Sqrt2-2)*math.Min(dr, dc)
+	}
+
+	cost := map[gridCell]float64{start: 0}
+	parent := map[gridCell]gridCell{}
+	open := &cellQueue{}
+	heap.Push(open, queuedCell{cell: start, priority: heuristic(start)})
+
+	for open.Len() > 0 {
+		current := heap.Pop(open).(queuedCell).cell
+		if current == goal {
+			path := []gridCell{current}
+			for current != start {
+				current = parent[current]
+				path = append([]gridCell{current}, path...)
+			}
+			return path, true
+		}
+
+		for dr := -1; dr <= 1; dr++ {
+			for dc := -1; dc <= 1; dc++ {
+				if dr == 0 && dc == 0 {
+					continue
+				}
+				next := gridCell{current.row + dr, current.col + dc}
+				if !g.free(next) {
+					continue
+				}
+				step := 1.0
+				if dr != 0 && dc != 0 {
+					step = math.Sqrt2
+				}
+				nextCost := cost[current] + step
+				if known, seen := cost[next]; seen && known <= nextCost {
+					continue
+				}
+				cost[next] = nextCost
+				parent[next] = current
+				heap.Push(open, queuedCell{cell: next, priority: nextCost + heuristic(next)})
+			}
+		}
+	}
+
+	return nil, false
+}
+
+type queuedCell struct {
+	cell     gridCell
+	priority float64
+}
+
+type cellQueue []queuedCell
+
+func (q cellQueue) Len() int            { return len(q) }
+func (q cellQueue) Less(i, j int) bool  { return q[i].priority < q[j].priority }
+func (q cellQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *cellQueue) Push(x interface{}) { *q = append(*q, x.(queuedCell)) }
+func (q *cellQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
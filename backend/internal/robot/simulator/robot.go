@@ -3,6 +3,7 @@ package simulator
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/chungweeeei/Temporal-robot-project/pkg"
@@ -11,14 +12,14 @@ import (
 // Handle Request
 func (r *MockRobot) HandleRequest(request pkg.RobotServiceRequest) pkg.RobotServiceResponse {
 
-	r.InfoLog.Printf("[%s] Receive Service Request: %s\n", time.Now(), request)
+	r.Logger.Info("Receive service request", "service", request.Service, "data", request.Args.Data)
 
 	switch request.Service {
 	case "/api/system":
 		// pre-processing data casting
 		requestDataStr, ok := request.Args.Data.(string)
 		if !ok {
-			r.ErrorLog.Println("Invalid request data format")
+			r.Logger.Error("Invalid request data format", "service", request.Service)
 			return pkg.RobotServiceResponse{
 				Op:      "service_response",
 				Service: request.Service,
@@ -34,7 +35,7 @@ func (r *MockRobot) HandleRequest(request pkg.RobotServiceRequest) pkg.RobotServ
 		// First validate request payload (Partial Decode)
 		var args BaseRequestArgs
 		if err := json.Unmarshal(requestDataBytes, &args); err != nil {
-			r.ErrorLog.Println("Error unmarshaling request args:", err)
+			r.Logger.Error("Error unmarshaling request args", "error", err)
 
 			respData := BaseResponse{
 				ApiID: args.ApiID,
@@ -65,6 +66,8 @@ func (r *MockRobot) HandleRequest(request pkg.RobotServiceRequest) pkg.RobotServ
 			return r.HandleTTSCommand(request.Service, requestDataBytes)
 		case RobotStopActionID:
 			return r.HandleStopCommand(request.Service, requestDataBytes)
+		case RobotChargeControlID:
+			return r.HandleChargeControl(request.Service, requestDataBytes)
 		default:
 			return r.HandleUnknownRequest(args.ApiID, request.Service)
 		}
@@ -72,7 +75,7 @@ func (r *MockRobot) HandleRequest(request pkg.RobotServiceRequest) pkg.RobotServ
 		// pre-processing data casting
 		requestAngle, ok := request.Args.Data.(float64)
 		if !ok {
-			r.ErrorLog.Println("Invalid request data format")
+			r.Logger.Error("Invalid request data format", "service", request.Service)
 			return pkg.RobotServiceResponse{
 				Op:      "service_response",
 				Service: request.Service,
@@ -90,70 +93,197 @@ func (r *MockRobot) HandleRequest(request pkg.RobotServiceRequest) pkg.RobotServ
 	}
 }
 
-func (r *MockRobot) Move(missionID string, targetX, targetY float64) {
+// Move drives to the target as mission missionID, preempting the running mission.
+// It returns once the mission ended.
+func (r *MockRobot) Move(missionID string, targetX, targetY, targetOrientation float64) {
 
-	fmt.Printf("Background move started: Target (%f, %f)\n", targetX, targetY)
+	m := r.startMission(MissionInfo{
+		ID:      missionID,
+		Target:  Pose{X: targetX, Y: targetY, Orientation: targetOrientation},
+		Started: time.Now(),
+	})
+	defer r.endMission(m)
+
+	r.Logger.Info("Background move started", "target_x", targetX, "target_y", targetY, "target_orientation", targetOrientation)
 
 	r.Mu.Lock()
 	startX := r.State.X
 	startY := r.State.Y
+	// moving away undocks the robot
+	r.State.Docked = false
+	r.State.Charging = false
 	// update mission status
 	r.State.MissionID = missionID
 	r.State.Mission.Code = MissionCodeStart
 	r.State.Mission.Message = "START"
 	r.Mu.Unlock()
 
-	// check if already at target
-	distance := ((targetX-startX)*(targetX-startX) + (targetY-startY)*(targetY-startY))
-	if distance < 0.05 {
-		r.InfoLog.Printf("Robot already at target location (%.2f, %.2f)\n", targetX, targetY)
+	r.Faults.missionStarted()
+	defer r.Faults.missionEnded()
+
+	// Phase 1: rotate toward each waypoint, then drive along the line, skipped when already there
+	distance := math.Hypot(targetX-startX, targetY-startY)
+	if distance >= r.Kinematics.Tolerance {
+		start, target := Point{X: startX, Y: startY}, Point{X: targetX, Y: targetY}
+		waypoints := []Point{target}
+
+		if r.Map != nil {
+			if r.Map.Occupied(targetX, targetY) {
+				r.Logger.Info("Move command failed, target is inside an obstacle", "target_x", targetX, "target_y", targetY)
+				r.setMissionResult(m, MissionFailed, "FAILED")
+				return
+			}
+
+			path, ok := r.Map.PlanPath(start, target)
+			if !ok {
+				// no way around: drive until the obstacle and stay stuck until stopped
+				r.Logger.Info("No path to target, robot will get stuck", "target_x", targetX, "target_y", targetY)
+				r.driveUntilBlocked(m, start, target)
+				return
+			}
+			waypoints = path
+		}
+
+		if !r.followPath(m, waypoints) {
+			r.setMissionResult(m, MissionAbort, "ABORT")
+			return
+		}
+	} else {
+		r.Logger.Info("Robot already at target location", "target_x", targetX, "target_y", targetY)
+	}
+
+	// Phase 2: turn to the requested orientation
+	if !r.rotateTo(m, targetOrientation) {
+		r.setMissionResult(m, MissionAbort, "ABORT")
+		return
+	}
+
+	r.Mu.Lock()
+	r.State.X = targetX
+	r.State.Y = targetY
+	r.State.Orientation = normalizeAngle(targetOrientation)
+	r.Mu.Unlock()
+	r.setMissionResult(m, MissionSuccess, "SUCCESS")
+
+	r.Logger.Info("Robot reached target location", "target_x", targetX, "target_y", targetY)
+}
+
+// followPath drives through every waypoint, reporting false when the mission was stopped.
+func (r *MockRobot) followPath(m *mission, waypoints []Point) bool {
+
+	for _, waypoint := range waypoints {
 		r.Mu.Lock()
-		r.State.Mission.Code = MissionSuccess
-		r.State.Mission.Message = "SUCCESS"
+		dx, dy := waypoint.X-r.State.X, waypoint.Y-r.State.Y
 		r.Mu.Unlock()
+
+		if math.Hypot(dx, dy) < r.Kinematics.Tolerance {
+			continue
+		}
+
+		heading := math.Atan2(dy, dx) * (180.0 / math.Pi)
+		if !r.rotateTo(m, heading) || !r.driveTo(m, waypoint.X, waypoint.Y) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// driveUntilBlocked heads straight for an unreachable target, stops in front of the obstacle
+// and keeps the mission running so the caller's stuck detection or timeout has to end it.
+func (r *MockRobot) driveUntilBlocked(m *mission, start, target Point) {
+
+	free := r.Map.FreeDistance(start, target)
+	length := math.Hypot(target.X-start.X, target.Y-start.Y)
+	blockedAt := Point{
+		X: start.X + (target.X-start.X)*free/length,
+		Y: start.Y + (target.Y-start.Y)*free/length,
+	}
+
+	if !r.followPath(m, []Point{blockedAt}) {
+		r.setMissionResult(m, MissionAbort, "ABORT")
 		return
 	}
 
-	// simple simulation: move step by step
-	// assume it takes 10 seconds to reach the target
-	// update position every 0.1 second
-	const durationSeconds = 60
-	const updatesPerSecond = 10
-	steps := durationSeconds * updatesPerSecond
-	interval := time.Duration(1000/updatesPerSecond) * time.Millisecond
-
-	// listen for the stop channel
-	for i := 1; i < steps; i++ {
-		select {
-		case <-r.StopChan:
-			r.InfoLog.Println("Move command stopped by Stop signal")
-			r.Mu.Lock()
-			r.State.Mission.Code = MissionAbort
-			r.State.Mission.Message = "ABORT"
-			r.Mu.Unlock()
-			return
-		default:
+	r.Logger.Info("Robot blocked", "x", blockedAt.X, "y", blockedAt.Y)
+	for r.waitTick(m) {
+	}
+	r.setMissionResult(m, MissionAbort, "ABORT")
+}
+
+// waitTick sleeps one update interval and reports false when the mission was stopped or aborted.
+func (r *MockRobot) waitTick(m *mission) bool {
+	select {
+	case <-m.ctx.Done():
+		r.Logger.Info("Move command stopped", "mission_id", m.info.ID)
+		return false
+	case <-time.After(r.Kinematics.UpdateRate):
+		if r.Faults.TakeAbort() {
+			r.Logger.Info("Move command aborted by injected fault")
+			return false
 		}
-		time.Sleep(interval)
+		return true
+	}
+}
+
+// rotateTo turns in place toward heading (degree) at the configured angular speed.
+func (r *MockRobot) rotateTo(m *mission, heading float64) bool {
 
+	step := r.Kinematics.AngularSpeed * r.Kinematics.UpdateRate.Seconds()
+
+	for {
 		r.Mu.Lock()
-		progress := float64(i) / float64(steps)
+		diff := normalizeAngle(heading - r.State.Orientation)
+		r.Mu.Unlock()
 
-		r.State.X = startX + (targetX-startX)*progress
-		r.State.Y = startY + (targetY-startY)*progress
+		if math.Abs(diff) <= step {
+			r.Mu.Lock()
+			r.State.Orientation = normalizeAngle(heading)
+			r.Mu.Unlock()
+			return true
+		}
 
-		if i%updatesPerSecond == 0 {
-			fmt.Printf("Robot moving... (%.0f%%) Pos(%.2f, %.2f)\n", progress*100, r.State.X, r.State.Y)
+		if !r.waitTick(m) {
+			return false
 		}
+
+		r.Mu.Lock()
+		r.State.Orientation = normalizeAngle(r.State.Orientation + math.Copysign(step, diff))
 		r.Mu.Unlock()
 	}
+}
 
-	r.Mu.Lock()
-	r.State.X = targetX
-	r.State.Y = targetY
-	r.State.Mission.Code = MissionSuccess
-	r.State.Mission.Message = "SUCCESS"
-	r.Mu.Unlock()
+// driveTo moves straight to the target, interpolating the pose with the kinematic speed profile.
+func (r *MockRobot) driveTo(m *mission, targetX, targetY float64) bool {
+
+	speed := 0.0
+	ticks := 0
+	ticksPerSecond := int(time.Second / r.Kinematics.UpdateRate)
 
-	r.InfoLog.Printf("Robot reached target location (%.2f, %.2f)\n", targetX, targetY)
+	for {
+		r.Mu.Lock()
+		dx := targetX - r.State.X
+		dy := targetY - r.State.Y
+		r.Mu.Unlock()
+
+		remaining := math.Hypot(dx, dy)
+		if remaining < r.Kinematics.Tolerance {
+			return true
+		}
+
+		if !r.waitTick(m) {
+			return false
+		}
+
+		speed = r.Kinematics.nextSpeed(speed, remaining)
+		travel := math.Min(speed*r.Kinematics.UpdateRate.Seconds(), remaining)
+
+		r.Mu.Lock()
+		r.State.X += dx / remaining * travel
+		r.State.Y += dy / remaining * travel
+		if ticks++; ticksPerSecond > 0 && ticks%ticksPerSecond == 0 {
+			r.Logger.Info("Robot moving...", "speed", speed, "remaining", remaining-travel, "x", r.State.X, "y", r.State.Y)
+		}
+		r.Mu.Unlock()
+	}
 }
@@ -0,0 +1,115 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type FaultType string
+
+const (
+	FaultRejectMove     FaultType = "reject_next_move"
+	FaultAbortMission   FaultType = "abort_mission"
+	FaultDropWebsocket  FaultType = "drop_websocket"
+	FaultFreezeStatus   FaultType = "freeze_status"
+	FaultDelayResponses FaultType = "delay_responses"
+	FaultMalformedJSON  FaultType = "malformed_json"
+)
+
+// FaultStep arms one fault At after the scenario starts.
+type FaultStep struct {
+	At       time.Duration `yaml:"at"`
+	Type     FaultType     `yaml:"type"`
+	Count    int           `yaml:"count"`    // reject_next_move, malformed_json
+	After    time.Duration `yaml:"after"`    // abort_mission: time into the mission
+	Duration time.Duration `yaml:"duration"` // freeze_status, delay_responses
+	Delay    time.Duration `yaml:"delay"`    // delay_responses
+}
+
+type Scenario struct {
+	Name   string      `yaml:"name"`
+	Faults []FaultStep `yaml:"faults"`
+}
+
+func LoadScenario(path string) (*Scenario, error) {
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var scenario Scenario
+	if err := yaml.Unmarshal(raw, &scenario); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %v", path, err)
+	}
+
+	for i, step := range scenario.Faults {
+		if err := step.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid fault #%d in scenario %s: %v", i+1, path, err)
+		}
+	}
+
+	return &scenario, nil
+}
+
+func (s FaultStep) Validate() error {
+	switch s.Type {
+	case FaultRejectMove, FaultMalformedJSON, FaultAbortMission, FaultDropWebsocket:
+	case FaultFreezeStatus, FaultDelayResponses:
+		if s.Duration <= 0 {
+			return fmt.Errorf("%s requires a positive duration", s.Type)
+		}
+	default:
+		return fmt.Errorf("unknown fault type %q", s.Type)
+	}
+	if s.At < 0 || s.Count < 0 || s.After < 0 || s.Delay < 0 {
+		return fmt.Errorf("%s settings must not be negative", s.Type)
+	}
+	return nil
+}
+
+// Run arms the scenario faults against the robot on schedule until ctx is done.
+func (s *Scenario) Run(ctx context.Context, r *MockRobot) {
+
+	r.Logger.Info("Scenario started", "scenario", s.Name, "faults", len(s.Faults))
+
+	for _, step := range s.Faults {
+		timer := time.AfterFunc(step.At, func() {
+			r.InjectFault(step)
+		})
+		go func() {
+			<-ctx.Done()
+			timer.Stop()
+		}()
+	}
+}
+
+// InjectFault arms a single fault immediately.
+func (r *MockRobot) InjectFault(step FaultStep) {
+
+	count := step.Count
+	if count == 0 {
+		count = 1
+	}
+
+	r.Logger.Info("Injecting fault", "fault", step.Type)
+
+	switch step.Type {
+	case FaultRejectMove:
+		r.Faults.RejectNextMoves(count)
+	case FaultAbortMission:
+		r.Faults.AbortMission(step.After)
+	case FaultDropWebsocket:
+		r.Faults.DropConnections()
+	case FaultFreezeStatus:
+		r.Faults.FreezeStatus(step.Duration)
+	case FaultDelayResponses:
+		r.Faults.DelayResponses(step.Delay, step.Duration)
+	case FaultMalformedJSON:
+		r.Faults.MalformedMessages(count)
+	}
+}
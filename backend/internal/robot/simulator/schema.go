@@ -15,8 +15,11 @@ type StatusDetail struct {
 }
 
 type RobotStatus struct {
-	ApiID        int `json:"api_id"`
-	BatteryLevel int `json:"battery_level"`
+	ApiID        int     `json:"api_id"`
+	BatteryLevel int     `json:"battery_level"`
+	Charging     bool    `json:"charging"`
+	Docked       bool    `json:"docked"`
+	Posture      Posture `json:"posture"`
 	Pose         struct {
 		Orientation struct {
 			X float64 `json:"x"`
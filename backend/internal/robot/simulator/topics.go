@@ -0,0 +1,145 @@
+package simulator
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Topic is a simulated topic clients can subscribe to.
+// Message builds the "msg" field of each publish from the current robot state.
+type Topic struct {
+	Name    string
+	Type    string        // rosbridge message type
+	Rate    time.Duration // publish interval before throttling
+	Message func(r *MockRobot) interface{}
+}
+
+var (
+	topicsMu sync.RWMutex
+	topics   = map[string]Topic{}
+)
+
+// RegisterTopic adds or replaces a simulated topic.
+func RegisterTopic(topic Topic) {
+	topicsMu.Lock()
+	defer topicsMu.Unlock()
+	topics[topic.Name] = topic
+}
+
+func LookupTopic(name string) (Topic, bool) {
+	topicsMu.RLock()
+	defer topicsMu.RUnlock()
+	topic, ok := topics[name]
+	return topic, ok
+}
+
+// Topics lists the registered topic names.
+func Topics() []string {
+	topicsMu.RLock()
+	defer topicsMu.RUnlock()
+
+	names := make([]string, 0, len(topics))
+	for name := range topics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterTopic(Topic{
+		Name: "/api/info",
+		Type: "std_msgs/String",
+		Rate: time.Second,
+		Message: func(r *MockRobot) interface{} {
+			return r.GetRobotStatus().Msg
+		},
+	})
+	RegisterTopic(Topic{
+		Name:    "/odom",
+		Type:    "nav_msgs/Odometry",
+		Rate:    100 * time.Millisecond,
+		Message: odometryMessage,
+	})
+	RegisterTopic(Topic{
+		Name:    "/battery",
+		Type:    "sensor_msgs/BatteryState",
+		Rate:    5 * time.Second,
+		Message: batteryMessage,
+	})
+}
+
+type Quaternion struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+	W float64 `json:"w"`
+}
+
+type Vector3 struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+type Header struct {
+	Stamp   time.Time `json:"stamp"`
+	FrameID string    `json:"frame_id"`
+}
+
+type OdometryMessage struct {
+	Header Header `json:"header"`
+	Pose   struct {
+		Position    Vector3    `json:"position"`
+		Orientation Quaternion `json:"orientation"`
+	} `json:"pose"`
+}
+
+func odometryMessage(r *MockRobot) interface{} {
+
+	r.Mu.Lock()
+	defer r.Mu.Unlock()
+
+	message := OdometryMessage{Header: Header{Stamp: time.Now(), FrameID: "odom"}}
+	message.Pose.Position = Vector3{X: r.State.X, Y: r.State.Y}
+	qx, qy, qz, qw := transferOrientationToQuaternion(r.State.Orientation)
+	message.Pose.Orientation = Quaternion{X: qx, Y: qy, Z: qz, W: qw}
+	return message
+}
+
+// BatteryState power supply status values, as in sensor_msgs/BatteryState
+const (
+	PowerSupplyCharging    = 1
+	PowerSupplyDischarging = 2
+	PowerSupplyFull        = 4
+)
+
+type BatteryMessage struct {
+	Header            Header  `json:"header"`
+	Percentage        float64 `json:"percentage"` // 0 to 1
+	PowerSupplyStatus int     `json:"power_supply_status"`
+	Present           bool    `json:"present"`
+}
+
+func batteryMessage(r *MockRobot) interface{} {
+
+	r.Mu.Lock()
+	defer r.Mu.Unlock()
+
+	status := PowerSupplyDischarging
+	switch {
+	case r.State.BatteryLevel >= 100:
+		status = PowerSupplyFull
+	case r.State.Charging:
+		status = PowerSupplyCharging
+	}
+
+	return BatteryMessage{
+		Header:            Header{Stamp: time.Now(), FrameID: "base_link"},
+		Percentage:        math.Round(r.State.BatteryLevel) / 100,
+		PowerSupplyStatus: status,
+		Present:           true,
+	}
+}
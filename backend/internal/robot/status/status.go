@@ -0,0 +1,240 @@
+// Package status decodes the robot's /api/info topic into a typed RobotStatus.
+//
+// The robot firmware is not consistent about JSON types: numbers arrive as strings, mission ids
+// as numbers and optional fields as null. Decoding never panics: a field with an unusable value is
+// left at its zero value and counted in DecodeErrors, only a message without a device status fails.
+// Pose components have no usable zero, a null or missing one is reported like a garbage value.
+package status
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"strings"
+
+	config "github.com/chungweeeei/Temporal-robot-project/internal/config/activity"
+	"github.com/chungweeeei/Temporal-robot-project/utils"
+)
+
+var (
+	ErrInvalidMessage = errors.New("invalid status message")
+	ErrNoDeviceStatus = errors.New("status message has no device status")
+	ErrMissingValue   = errors.New("value is null or missing")
+)
+
+// DecodeErrors counts decode problems by field, "message" for messages that were dropped.
+// Published through expvar as robot_status_decode_errors.
+var DecodeErrors = expvar.NewMap("robot_status_decode_errors")
+
+// RawRobotStatus is the device status as sent by the robot, before type normalization.
+type RawRobotStatus struct {
+	ApiID        interface{} `json:"api_id"`
+	BatteryLevel interface{} `json:"battery_level"` // Could be string "94" or int 94
+	Charging     interface{} `json:"charging"`
+	Docked       interface{} `json:"docked"`
+	Posture      interface{} `json:"posture"`
+	Pose         struct {
+		Position struct {
+			X interface{} `json:"x"`
+			Y interface{} `json:"y"`
+			Z interface{} `json:"z"`
+		} `json:"position"`
+		Orientation struct {
+			X interface{} `json:"x"`
+			Y interface{} `json:"y"`
+			Z interface{} `json:"z"`
+			W interface{} `json:"w"`
+		} `json:"orientation"`
+	} `json:"pose"`
+	MissionID interface{} `json:"mission_id"`
+	Mission   struct {
+		Code    interface{} `json:"code"`
+		Message interface{} `json:"message"`
+	} `json:"mission"`
+}
+
+type RobotStatus struct {
+	ApiID        int            `json:"api_id"`
+	BatteryLevel int            `json:"battery_level"`
+	Charging     bool           `json:"charging"`
+	Docked       bool           `json:"docked"`
+	Posture      config.Posture `json:"posture"`
+	Pose         struct {
+		Orientation struct {
+			W float64 `json:"w"`
+			X float64 `json:"x"`
+			Y float64 `json:"y"`
+			Z float64 `json:"z"`
+		} `json:"orientation"`
+		Position struct {
+			X float64 `json:"x"`
+			Y float64 `json:"y"`
+			Z float64 `json:"z"`
+		} `json:"position"`
+	} `json:"pose"`
+	MissionID string `json:"mission_id"`
+	Mission   struct {
+		Code    config.MissionCode `json:"code"`
+		Message string             `json:"message"`
+	} `json:"mission"`
+}
+
+// FieldError is a device status field that could not be converted.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Field, e.Err)
+}
+
+// Decode parses a rosbridge publish message of the /api/info topic.
+// Field problems are returned next to the decoded status, they do not fail the decoding.
+func Decode(message []byte) (RobotStatus, []FieldError, error) {
+
+	var envelope struct {
+		Op    string `json:"op"`
+		Topic string `json:"topic"`
+		Msg   struct {
+			Data json.RawMessage `json:"data"`
+		} `json:"msg"`
+	}
+	if err := json.Unmarshal(message, &envelope); err != nil {
+		DecodeErrors.Add("message", 1)
+		return RobotStatus{}, nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+
+	// msg.data is a JSON encoded string on std_msgs/String, some firmware sends the object itself
+	data := []byte(envelope.Msg.Data)
+	var encoded string
+	if err := json.Unmarshal(data, &encoded); err == nil {
+		data = []byte(encoded)
+	}
+
+	var info struct {
+		DeviceName   string          `json:"device_name"`
+		DeviceStatus *RawRobotStatus `json:"device_status"`
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		DecodeErrors.Add("message", 1)
+		return RobotStatus{}, nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+	if info.DeviceStatus == nil || info.DeviceStatus.BatteryLevel == nil {
+		DecodeErrors.Add("message", 1)
+		return RobotStatus{}, nil, ErrNoDeviceStatus
+	}
+
+	status, fieldErrors := Normalize(*info.DeviceStatus)
+	return status, fieldErrors, nil
+}
+
+// Normalize converts a raw device status, collecting the fields it had to zero.
+func Normalize(raw RawRobotStatus) (RobotStatus, []FieldError) {
+
+	d := &decoder{}
+	var status RobotStatus
+
+	status.ApiID = d.int("api_id", raw.ApiID)
+	status.BatteryLevel = d.int("battery_level", raw.BatteryLevel)
+	status.Charging = d.bool("charging", raw.Charging)
+	status.Docked = d.bool("docked", raw.Docked)
+	status.Posture = config.Posture(d.string("posture", raw.Posture))
+
+	// a null pose component is no position, 0 would move the robot to the origin
+	status.Pose.Position.X = d.requiredFloat("pose.position.x", raw.Pose.Position.X)
+	status.Pose.Position.Y = d.requiredFloat("pose.position.y", raw.Pose.Position.Y)
+	status.Pose.Position.Z = d.requiredFloat("pose.position.z", raw.Pose.Position.Z)
+	status.Pose.Orientation.X = d.requiredFloat("pose.orientation.x", raw.Pose.Orientation.X)
+	status.Pose.Orientation.Y = d.requiredFloat("pose.orientation.y", raw.Pose.Orientation.Y)
+	status.Pose.Orientation.Z = d.requiredFloat("pose.orientation.z", raw.Pose.Orientation.Z)
+	status.Pose.Orientation.W = d.requiredFloat("pose.orientation.w", raw.Pose.Orientation.W)
+
+	status.MissionID = d.string("mission_id", raw.MissionID)
+	status.Mission.Code = config.MissionCode(d.int("mission.code", raw.Mission.Code))
+	status.Mission.Message = d.string("mission.message", raw.Mission.Message)
+
+	return status, d.errors
+}
+
+type decoder struct {
+	errors []FieldError
+}
+
+func (d *decoder) fail(field string, err error) {
+	d.errors = append(d.errors, FieldError{Field: field, Err: err})
+	DecodeErrors.Add(field, 1)
+}
+
+func (d *decoder) int(field string, v interface{}) int {
+	i, err := utils.TryInt(v)
+	if err != nil {
+		d.fail(field, err)
+	}
+	return i
+}
+
+func (d *decoder) float(field string, v interface{}) float64 {
+	f, err := utils.TryFloat(v)
+	if err != nil {
+		d.fail(field, err)
+	}
+	return f
+}
+
+// requiredFloat is float for values that may not be null or missing.
+func (d *decoder) requiredFloat(field string, v interface{}) float64 {
+	if v == nil {
+		d.fail(field, ErrMissingValue)
+		return 0
+	}
+	return d.float(field, v)
+}
+
+func (d *decoder) bool(field string, v interface{}) bool {
+	b, err := utils.TryBool(v)
+	if err != nil {
+		d.fail(field, err)
+	}
+	return b
+}
+
+func (d *decoder) string(field string, v interface{}) string {
+	s, err := utils.TryString(v)
+	if err != nil {
+		d.fail(field, err)
+	}
+	return s
+}
+
+// KeepLastGood puts the battery level and pose of last into status where they could not be
+// decoded: a zeroed battery would send the robot to the dock and a zeroed pose move it to the
+// origin. Without a last status such a reading is unusable and ok is false.
+func KeepLastGood(status RobotStatus, fieldErrors []FieldError, last *RobotStatus) (merged RobotStatus, ok bool) {
+
+	battery, pose := false, false
+	for _, fieldErr := range fieldErrors {
+		switch {
+		case fieldErr.Field == "battery_level":
+			battery = true
+		case strings.HasPrefix(fieldErr.Field, "pose."):
+			pose = true
+		}
+	}
+	if !battery && !pose {
+		return status, true
+	}
+	if last == nil {
+		return status, false
+	}
+
+	if battery {
+		status.BatteryLevel = last.BatteryLevel
+	}
+	// the pose is replaced as a whole, mixing components of two readings makes no position
+	if pose {
+		status.Pose = last.Pose
+	}
+	return status, true
+}
@@ -0,0 +1,170 @@
+package status
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fixture is a payload variant of test/robot-status, see the README there.
+type fixture struct {
+	Description string          `json:"description"`
+	Message     json.RawMessage `json:"message"`
+	Expected    *RobotStatus    `json:"expected"`
+	FieldErrors []string        `json:"field_errors"`
+	Error       string          `json:"error"`
+}
+
+const fixtureDir = "../../../test/robot-status"
+
+func loadFixture(t *testing.T, name string) fixture {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(fixtureDir, name+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f fixture
+	if err := json.Unmarshal(content, &f); err != nil {
+		t.Fatalf("invalid fixture %s: %v", name, err)
+	}
+	return f
+}
+
+// message is the websocket message, one that is not valid JSON is stored as a string.
+func (f fixture) message() []byte {
+	var text string
+	if err := json.Unmarshal(f.Message, &text); err == nil {
+		return []byte(text)
+	}
+	return []byte(f.Message)
+}
+
+func TestDecodeFixtures(t *testing.T) {
+
+	paths, err := filepath.Glob(filepath.Join(fixtureDir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no fixtures found")
+	}
+
+	sentinels := map[string]error{
+		ErrInvalidMessage.Error(): ErrInvalidMessage,
+		ErrNoDeviceStatus.Error(): ErrNoDeviceStatus,
+	}
+
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		t.Run(name, func(t *testing.T) {
+
+			f := loadFixture(t, name)
+			status, fieldErrors, err := Decode(f.message())
+
+			if f.Error != "" {
+				sentinel, known := sentinels[f.Error]
+				if !known {
+					t.Fatalf("unknown fixture error %q", f.Error)
+				}
+				if !errors.Is(err, sentinel) {
+					t.Fatalf("error = %v, want %v", err, sentinel)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if f.Expected == nil {
+				t.Fatal("fixture has neither expected nor error")
+			}
+			if !reflect.DeepEqual(status, *f.Expected) {
+				t.Errorf("status = %+v, want %+v", status, *f.Expected)
+			}
+
+			fields := []string{}
+			for _, fieldErr := range fieldErrors {
+				fields = append(fields, fieldErr.Field)
+			}
+			want := f.FieldErrors
+			if want == nil {
+				want = []string{}
+			}
+			if !reflect.DeepEqual(fields, want) {
+				t.Errorf("field errors = %v, want %v", fields, want)
+			}
+		})
+	}
+}
+
+func TestKeepLastGood(t *testing.T) {
+
+	var last RobotStatus
+	last.BatteryLevel = 64
+	last.Pose.Position.X = 1.5
+	last.Pose.Orientation.W = 1
+
+	var reading RobotStatus
+	reading.Charging = true
+	reading.Pose.Position.X = 3
+
+	tests := []struct {
+		name        string
+		fieldErrors []FieldError
+		last        *RobotStatus
+		wantOK      bool
+		wantBattery int
+		wantX       float64
+		wantW       float64
+	}{
+		{"no field errors", nil, nil, true, 0, 3, 0},
+		{"other field unusable", []FieldError{{Field: "posture"}}, nil, true, 0, 3, 0},
+		{"battery unusable", []FieldError{{Field: "battery_level"}}, &last, true, 64, 3, 0},
+		{"pose unusable", []FieldError{{Field: "pose.position.y"}}, &last, true, 0, 1.5, 1},
+		{"battery unusable without last status", []FieldError{{Field: "battery_level"}}, nil, false, 0, 0, 0},
+		{"pose unusable without last status", []FieldError{{Field: "pose.orientation.w"}}, nil, false, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, ok := KeepLastGood(reading, tt.fieldErrors, tt.last)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if merged.BatteryLevel != tt.wantBattery || merged.Pose.Position.X != tt.wantX || merged.Pose.Orientation.W != tt.wantW {
+				t.Errorf("battery %d, x %v, w %v, want %d, %v, %v",
+					merged.BatteryLevel, merged.Pose.Position.X, merged.Pose.Orientation.W, tt.wantBattery, tt.wantX, tt.wantW)
+			}
+			if !merged.Charging {
+				t.Error("decoded fields must be kept")
+			}
+		})
+	}
+}
+
+func TestNullPoseKeepsLastGood(t *testing.T) {
+
+	last := loadFixture(t, "simulator").Expected
+	f := loadFixture(t, "null-fields")
+
+	status, fieldErrors, err := Decode(f.message())
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged, ok := KeepLastGood(status, fieldErrors, last)
+	if !ok {
+		t.Fatal("status with a last good pose was dropped")
+	}
+	if merged.Pose != last.Pose {
+		t.Errorf("pose = %+v, want the last good pose %+v", merged.Pose, last.Pose)
+	}
+	if merged.BatteryLevel != f.Expected.BatteryLevel {
+		t.Errorf("battery level = %d, want the decoded %d", merged.BatteryLevel, f.Expected.BatteryLevel)
+	}
+}
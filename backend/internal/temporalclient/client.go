@@ -0,0 +1,113 @@
+// Package temporalclient dials Temporal with the connection settings shared by the REST server and the worker.
+package temporalclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+
+	"github.com/chungweeeei/Temporal-robot-project/internal/config/settings"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+)
+
+// DataConverterFactory builds a named data converter from the Temporal settings.
+type DataConverterFactory func(config settings.TemporalConfig) (converter.DataConverter, error)
+
+var dataConverters = map[string]DataConverterFactory{
+	"default": func(settings.TemporalConfig) (converter.DataConverter, error) {
+		return converter.GetDefaultDataConverter(), nil
+	},
+	// zlib compresses payloads whenever that makes them smaller, e.g. big workflow definitions
+	"zlib": func(settings.TemporalConfig) (converter.DataConverter, error) {
+		return converter.NewCodecDataConverter(converter.GetDefaultDataConverter(),
+			converter.NewZlibCodec(converter.ZlibCodecOptions{})), nil
+	},
+}
+
+// RegisterDataConverter makes a data converter selectable by name in temporal.data_converter.
+func RegisterDataConverter(name string, factory DataConverterFactory) {
+	dataConverters[name] = factory
+}
+
+// DataConverter returns the configured data converter.
+func DataConverter(config settings.TemporalConfig) (converter.DataConverter, error) {
+	factory, exists := dataConverters[config.DataConverter]
+	if !exists {
+		names := []string{}
+		for name := range dataConverters {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown data converter %q, available: %v", config.DataConverter, names)
+	}
+	return factory(config)
+}
+
+// Dial connects with options completed by the settings: address, namespace, TLS or mTLS,
+// API key and data converter.
+func Dial(config settings.TemporalConfig, options client.Options) (client.Client, error) {
+
+	options.HostPort = config.HostPort
+	options.Namespace = config.Namespace
+
+	dataConverter, err := DataConverter(config)
+	if err != nil {
+		return nil, err
+	}
+	options.DataConverter = dataConverter
+
+	if config.TLS.Active() {
+		tlsConfig, err := tlsConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		options.ConnectionOptions.TLS = tlsConfig
+	}
+
+	if config.APIKey != "" {
+		options.Credentials = client.NewAPIKeyStaticCredentials(config.APIKey)
+	}
+
+	return client.Dial(options)
+}
+
+func tlsConfig(config settings.TemporalConfig) (*tls.Config, error) {
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: config.TLS.ServerName,
+	}
+	if tlsConfig.ServerName == "" {
+		host, _, err := net.SplitHostPort(config.HostPort)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ServerName = host
+	}
+
+	if config.TLS.CAFile != "" {
+		caPEM, err := os.ReadFile(config.TLS.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read Temporal CA: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in %s", config.TLS.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.TLS.CertFile != "" {
+		certificate, err := tls.LoadX509KeyPair(config.TLS.CertFile, config.TLS.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load Temporal client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
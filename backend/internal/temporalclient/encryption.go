@@ -0,0 +1,121 @@
+package temporalclient
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+
+	"github.com/chungweeeei/Temporal-robot-project/internal/config/settings"
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/sdk/converter"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	encodingEncrypted       = "binary/encrypted"
+	metadataEncryptionKeyID = "encryption-key-id"
+)
+
+func init() {
+	RegisterDataConverter(settings.EncryptionDataConverter, func(config settings.TemporalConfig) (converter.DataConverter, error) {
+		codec, err := NewEncryptionCodec(config.Encryption)
+		if err != nil {
+			return nil, err
+		}
+		return converter.NewCodecDataConverter(converter.GetDefaultDataConverter(), codec), nil
+	})
+}
+
+// EncryptionCodec encrypts whole payloads, metadata included, with AES-GCM. The key id is kept
+// in plaintext metadata so rotated keys still decrypt older history, payloads written before
+// encryption was enabled pass through.
+type EncryptionCodec struct {
+	keyID string
+	aeads map[string]cipher.AEAD
+}
+
+func NewEncryptionCodec(config settings.EncryptionConfig) (*EncryptionCodec, error) {
+
+	keys, err := config.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	codec := &EncryptionCodec{keyID: config.KeyID, aeads: map[string]cipher.AEAD{}}
+	for keyID, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		codec.aeads[keyID] = aead
+	}
+
+	return codec, nil
+}
+
+func (c *EncryptionCodec) Encode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+
+	aead := c.aeads[c.keyID]
+	result := make([]*commonpb.Payload, len(payloads))
+	for i, payload := range payloads {
+		plaintext, err := proto.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, err
+		}
+
+		// the key id is authenticated, it can not be swapped to another key
+		result[i] = &commonpb.Payload{
+			Metadata: map[string][]byte{
+				converter.MetadataEncoding: []byte(encodingEncrypted),
+				metadataEncryptionKeyID:    []byte(c.keyID),
+			},
+			Data: aead.Seal(nonce, nonce, plaintext, []byte(c.keyID)),
+		}
+	}
+
+	return result, nil
+}
+
+func (c *EncryptionCodec) Decode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+
+	result := make([]*commonpb.Payload, len(payloads))
+	for i, payload := range payloads {
+		if string(payload.Metadata[converter.MetadataEncoding]) != encodingEncrypted {
+			result[i] = payload
+			continue
+		}
+
+		keyID := string(payload.Metadata[metadataEncryptionKeyID])
+		aead, exists := c.aeads[keyID]
+		if !exists {
+			return nil, fmt.Errorf("payload encrypted with unknown key %q", keyID)
+		}
+		if len(payload.Data) < aead.NonceSize() {
+			return nil, fmt.Errorf("encrypted payload is too short")
+		}
+
+		nonce, ciphertext := payload.Data[:aead.NonceSize()], payload.Data[aead.NonceSize():]
+		plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(keyID))
+		if err != nil {
+			return nil, fmt.Errorf("unable to decrypt payload with key %q: %v", keyID, err)
+		}
+
+		decoded := &commonpb.Payload{}
+		if err := proto.Unmarshal(plaintext, decoded); err != nil {
+			return nil, err
+		}
+		result[i] = decoded
+	}
+
+	return result, nil
+}
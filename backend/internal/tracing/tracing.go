@@ -0,0 +1,87 @@
+// Package tracing follows a request with OpenTelemetry from the REST API through Temporal
+// workflows and activities down to the rosbridge frames sent to the robot.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chungweeeei/Temporal-robot-project/internal/config/settings"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	temporalotel "go.temporal.io/sdk/contrib/opentelemetry"
+	"go.temporal.io/sdk/interceptor"
+)
+
+const instrumentationName = "github.com/chungweeeei/Temporal-robot-project"
+
+// recordFrames is settings.TracingConfig.RecordFrames of the running binary.
+var recordFrames bool
+
+// Setup installs the global tracer provider and the W3C trace context propagator.
+// The returned function flushes pending spans, call it before exiting.
+func Setup(config settings.TracingConfig, serviceName string) (func(context.Context) error, error) {
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	recordFrames = config.RecordFrames
+
+	var export sdktrace.TracerProviderOption
+	switch config.Exporter {
+	case settings.TracingNone:
+		return func(context.Context) error { return nil }, nil
+	case settings.TracingStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, err
+		}
+		// spans are printed as they end
+		export = sdktrace.WithSyncer(exporter)
+	case settings.TracingOTLP:
+		exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(config.OTLPEndpoint))
+		if err != nil {
+			return nil, err
+		}
+		export = sdktrace.WithBatcher(exporter)
+	default:
+		return nil, fmt.Errorf("unknown span exporter %q", config.Exporter)
+	}
+
+	serviceResource, err := resource.New(context.Background(),
+		resource.WithAttributes(semconv.ServiceName(serviceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(export, sdktrace.WithResource(serviceResource))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// RecordFrames tells whether span events may hold the plaintext frames sent to the robot.
+func RecordFrames() bool {
+	return recordFrames
+}
+
+// Tracer starts the spans of this project.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// TemporalInterceptor carries the trace context in Temporal headers. Set on the client it
+// traces ExecuteWorkflow and signals, and workers created from that client trace the
+// workflow and each activity as children of the caller's span.
+func TemporalInterceptor() (interceptor.Interceptor, error) {
+	return temporalotel.NewTracingInterceptor(temporalotel.TracerOptions{
+		TextMapPropagator: otel.GetTextMapPropagator(),
+	})
+}
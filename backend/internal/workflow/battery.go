@@ -0,0 +1,74 @@
+package workflow
+
+import (
+	"time"
+
+	"github.com/chungweeeei/Temporal-robot-project/pkg"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// The battery monitor lives as long as the run, so it only relies on heartbeats for liveness.
+var batteryMonitorActivityOptions = workflow.ActivityOptions{
+	StartToCloseTimeout: 24 * time.Hour,
+	HeartbeatTimeout:    10 * time.Second,
+	RetryPolicy: &temporal.RetryPolicy{
+		InitialInterval:    time.Second * 5,
+		MaximumInterval:    time.Minute,
+		BackoffCoefficient: 2.0,
+	},
+}
+
+// Docking and charging outlast the motion timeout, a charge to the target level can take hours.
+// The robot is not retried, a failure goes to the node's failure transition like any other node.
+var (
+	dockActivityOptions = workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Minute,
+		HeartbeatTimeout:    30 * time.Second,
+		WaitForCancellation: true,
+		RetryPolicy:         &temporal.RetryPolicy{MaximumAttempts: 1},
+	}
+	chargeActivityOptions = workflow.ActivityOptions{
+		StartToCloseTimeout: 12 * time.Hour,
+		HeartbeatTimeout:    30 * time.Second,
+		WaitForCancellation: true,
+		RetryPolicy:         &temporal.RetryPolicy{MaximumAttempts: 1},
+	}
+)
+
+// nodeActivityOptions replace the default activity options for these node types.
+var nodeActivityOptions = map[pkg.ActivityType]workflow.ActivityOptions{
+	pkg.ActivityDock:   dockActivityOptions,
+	pkg.ActivityCharge: chargeActivityOptions,
+}
+
+// watchBattery runs MonitorBattery in the background and calls onCritical once the
+// battery reaches the policy's critical level. Cancel ctx to stop watching.
+func watchBattery(ctx workflow.Context, policy pkg.BatteryPolicy, onCritical func(level int)) {
+
+	logger := workflow.GetLogger(ctx)
+
+	workflow.Go(ctx, func(ctx workflow.Context) {
+		monitorCtx := workflow.WithActivityOptions(ctx, batteryMonitorActivityOptions)
+
+		var level int
+		err := workflow.ExecuteActivity(monitorCtx, "MonitorBattery", policy.CriticalLevel).Get(monitorCtx, &level)
+		if err != nil {
+			if !temporal.IsCanceledError(err) {
+				logger.Error("Battery monitor failed", "error", err)
+			}
+			return
+		}
+		onCritical(level)
+	})
+}
+
+// activityArgs are the arguments of a robot activity node. A Move of the return-to-dock
+// sub-flow skips the battery guard, node params can never do that.
+func activityArgs(node pkg.WorkflowNode, docking bool) []interface{} {
+
+	if node.Type != pkg.ActivityMove {
+		return []interface{}{node.Params}
+	}
+	return []interface{}{node.Params, pkg.MoveOptions{SkipBatteryGuard: docking}}
+}
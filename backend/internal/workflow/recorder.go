@@ -0,0 +1,72 @@
+package workflow
+
+import (
+	"time"
+
+	"github.com/chungweeeei/Temporal-robot-project/internal/logging"
+	"github.com/chungweeeei/Temporal-robot-project/pkg"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Recording is best effort: a database outage must never fail a robot run.
+var recorderActivityOptions = workflow.ActivityOptions{
+	StartToCloseTimeout: 10 * time.Second,
+	RetryPolicy: &temporal.RetryPolicy{
+		InitialInterval:    time.Second,
+		MaximumInterval:    5 * time.Second,
+		MaximumAttempts:    3,
+		BackoffCoefficient: 2.0,
+	},
+}
+
+// executionRecordingChange versions the recording activities: runs started before them have
+// none in their history and must replay without them.
+const executionRecordingChange = "execution-recording"
+
+// recordingEnabled returns the same answer for the whole run, the first call fixes the version.
+func recordingEnabled(ctx workflow.Context) bool {
+	return workflow.GetVersion(ctx, executionRecordingChange, workflow.DefaultVersion, 1) >= 1
+}
+
+func recordExecution(ctx workflow.Context, activityName string, event pkg.ExecutionEvent) {
+	if !recordingEnabled(ctx) {
+		return
+	}
+	ctx = workflow.WithActivityOptions(ctx, recorderActivityOptions)
+	if err := workflow.ExecuteActivity(ctx, activityName, event).Get(ctx, nil); err != nil {
+		workflow.GetLogger(ctx).Warn("Failed to record execution", "activity", activityName, "error", err)
+	}
+}
+
+func recordNodeExecution(ctx workflow.Context, event pkg.NodeExecutionEvent) {
+	if !recordingEnabled(ctx) {
+		return
+	}
+	// a cancelled run still records the node it was cancelled in
+	if ctx.Err() != nil {
+		ctx, _ = workflow.NewDisconnectedContext(ctx)
+	}
+	ctx = workflow.WithActivityOptions(ctx, recorderActivityOptions)
+	if err := workflow.ExecuteActivity(ctx, "RecordNodeExecution", event).Get(ctx, nil); err != nil {
+		workflow.GetLogger(ctx).Warn("Failed to record node execution", logging.NodeID, event.NodeID, "error", err)
+	}
+}
+
+func executionStatus(err error) pkg.ExecutionStatus {
+	switch {
+	case err == nil:
+		return pkg.ExecutionCompleted
+	case temporal.IsCanceledError(err):
+		return pkg.ExecutionCanceled
+	default:
+		return pkg.ExecutionFailed
+	}
+}
+
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,56 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chungweeeei/Temporal-robot-project/pkg"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// ControlSignalName is reserved for pause / resume and can not be awaited by a WaitForSignal node.
+const ControlSignalName = "control-signal"
+
+// decodeControlSignal accepts a pkg.ControlSignal or the bare "pause" / "resume" string
+// sent by older clients.
+func decodeControlSignal(raw interface{}) pkg.ControlSignal {
+	switch value := raw.(type) {
+	case string:
+		return pkg.ControlSignal{Action: value}
+	case map[string]interface{}:
+		action, _ := value["action"].(string)
+		actor, _ := value["actor"].(string)
+		return pkg.ControlSignal{Action: action, Actor: actor}
+	default:
+		return pkg.ControlSignal{}
+	}
+}
+
+// waitForSignal blocks until the named signal arrives, the timeout fires or ctx is cancelled.
+// A zero timeout waits forever.
+func waitForSignal(ctx workflow.Context, signalName string, timeout time.Duration) (interface{}, error) {
+
+	var payload interface{}
+	var err error
+
+	selector := workflow.NewSelector(ctx)
+	selector.AddReceive(workflow.GetSignalChannel(ctx, signalName), func(c workflow.ReceiveChannel, more bool) {
+		c.Receive(ctx, &payload)
+	})
+	selector.AddReceive(ctx.Done(), func(c workflow.ReceiveChannel, more bool) {
+		err = temporal.NewCanceledError()
+	})
+	if timeout > 0 {
+		selector.AddFuture(workflow.NewTimer(ctx, timeout), func(f workflow.Future) {
+			if timerErr := f.Get(ctx, nil); timerErr != nil {
+				err = timerErr
+				return
+			}
+			err = fmt.Errorf("timed out after %s waiting for signal %s", timeout, signalName)
+		})
+	}
+	selector.Select(ctx)
+
+	return payload, err
+}
@@ -4,15 +4,37 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/chungweeeei/Temporal-robot-project/internal/audit"
+	"github.com/chungweeeei/Temporal-robot-project/internal/logging"
 	"github.com/chungweeeei/Temporal-robot-project/pkg"
 	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 )
 
-func RobotWorkflow(ctx workflow.Context, payload pkg.WorkflowPayload) (string, error) {
+func RobotWorkflow(ctx workflow.Context, payload pkg.WorkflowPayload) (result string, err error) {
 
 	logger := workflow.GetLogger(ctx)
-	logger.Info("Robot workflow started", "payload", payload)
+	logger.Info("Robot workflow started", "payload", payload, "actor", audit.ActorFromWorkflow(ctx))
+
+	// persist run outcome in Postgres
+	runID := workflow.GetInfo(ctx).WorkflowExecution.RunID
+	recordExecution(ctx, "RecordExecutionStart", pkg.ExecutionEvent{
+		RunID:      runID,
+		WorkflowID: payload.WorkflowID,
+		ScheduleID: payload.ScheduleID,
+		Status:     pkg.ExecutionRunning,
+		Time:       workflow.Now(ctx),
+	})
+	defer func() {
+		// disconnected context so the outcome is still recorded when the run is cancelled
+		recordCtx, _ := workflow.NewDisconnectedContext(ctx)
+		recordExecution(recordCtx, "RecordExecutionEnd", pkg.ExecutionEvent{
+			RunID:  runID,
+			Status: executionStatus(err),
+			Error:  errorMessage(err),
+			Time:   workflow.Now(recordCtx),
+		})
+	}()
 
 	// validate received payload
 	if payload.RootNodeID == "" {
@@ -36,16 +58,16 @@ func RobotWorkflow(ctx workflow.Context, payload pkg.WorkflowPayload) (string, e
 	// Register signal for stop & resume workflow
 	pause := false
 	var cancelCurrentActivity func()
-	signalChan := workflow.GetSignalChannel(ctx, "control-signal")
+	signalChan := workflow.GetSignalChannel(ctx, ControlSignalName)
 
 	// Background listener for control signal
 	workflow.Go(ctx, func(ctx workflow.Context) {
-		workflowID := workflow.GetInfo(ctx).WorkflowExecution.ID
 		for {
-			var signal string
-			signalChan.Receive(ctx, &signal)
-			logger.Info("Received control signal", "workflowID", workflowID, "signal", signal)
-			switch signal {
+			var raw interface{}
+			signalChan.Receive(ctx, &raw)
+			signal := decodeControlSignal(raw)
+			logger.Info("Received control signal", "signal", signal.Action, "actor", signal.Actor)
+			switch signal.Action {
 			case "pause":
 				pause = true
 				if cancelCurrentActivity != nil {
@@ -59,29 +81,68 @@ func RobotWorkflow(ctx workflow.Context, payload pkg.WorkflowPayload) (string, e
 
 	currentNodeID := payload.RootNodeID
 	currentStep := "Initializing"
+	waitingSignal := ""
+	variables := map[string]interface{}{}
+
+	// Battery policy: jump to the return-to-dock sub-flow once the battery turns critical
+	lowBattery := false
+	docking := false
+	if payload.BatteryPolicy != nil && payload.BatteryPolicy.ReturnToDockNodeID != "" {
+		monitorCtx, cancelMonitor := workflow.WithCancel(ctx)
+		defer cancelMonitor()
+
+		watchBattery(monitorCtx, *payload.BatteryPolicy, func(level int) {
+			logger.Warn("Battery level critical, interrupting flow", "battery_level", level)
+			lowBattery = true
+			if cancelCurrentActivity != nil {
+				cancelCurrentActivity()
+			}
+		})
+	}
 
 	workflow.SetQueryHandler(ctx, "get_step", func() (map[string]interface{}, error) {
 		if pause {
 			return map[string]interface{}{
-				"nodeId": currentNodeID,
-				"step":   "Paused",
+				"nodeId":    currentNodeID,
+				"step":      "Paused",
+				"signal":    waitingSignal,
+				"variables": variables,
+				"docking":   docking,
 			}, nil
 		}
 		return map[string]interface{}{
-			"nodeId": currentNodeID,
-			"step":   currentStep,
+			"nodeId":    currentNodeID,
+			"step":      currentStep,
+			"signal":    waitingSignal,
+			"variables": variables,
+			"docking":   docking,
 		}, nil
 	})
 
+	// A cancel of the run cancels ctx, a pause only the node's childCtx: canceled errors loop
+	// back to the pause check while ctx is alive and end the run otherwise.
 	for {
 		// 使用 workflow.Await 來等待 paused 狀態解除
 		// 這裡會阻塞直到匿名函數返回 true (即 !paused)
 		// 這樣在任何 Activity 執行"前"，都會檢查是否暫停
-		workflow.Await(ctx, func() bool { return !pause })
+		// a critical battery overrides pause, the robot must get back to the dock
+		if err := workflow.Await(ctx, func() bool { return !pause || (lowBattery && !docking) }); err != nil {
+			return "", temporal.NewCanceledError()
+		}
+
+		if lowBattery && !docking {
+			docking = true
+			pause = false
+			currentNodeID = payload.BatteryPolicy.ReturnToDockNodeID
+			logger.Info("Running return to dock sub-flow", logging.NodeID, currentNodeID)
+		}
 
 		// Register children cancel context
 		childCtx, cancel := workflow.WithCancel(ctx)
 		cancelCurrentActivity = cancel
+		// the node's activity and its log lines carry the node ID
+		childCtx = logging.WithWorkflow(childCtx, logging.NodeID, currentNodeID)
+		logger := workflow.GetLogger(childCtx)
 
 		currentNode, exists := payload.Nodes[currentNodeID]
 		if !exists {
@@ -91,18 +152,35 @@ func RobotWorkflow(ctx workflow.Context, payload pkg.WorkflowPayload) (string, e
 
 		currentStep = string(currentNode.Type)
 		switch currentNode.Type {
-		case pkg.ActivityStandUp, pkg.ActivitySitDown, pkg.ActivityHead, pkg.ActivityMove, pkg.ActivityTTS:
+		case pkg.ActivityStandUp, pkg.ActivityStandDown, pkg.ActivitySitDown, pkg.ActivityHead, pkg.ActivityMove, pkg.ActivityTTS, pkg.ActivityDock, pkg.ActivityCharge:
 			// Execute robot activity
-			var result string
-			err := workflow.ExecuteActivity(childCtx, string(currentNode.Type), currentNode.Params).Get(childCtx, &result)
+			if options, exists := nodeActivityOptions[currentNode.Type]; exists {
+				childCtx = workflow.WithActivityOptions(childCtx, options)
+			}
+			var activityResult string
+			startTime := workflow.Now(ctx)
+			err := workflow.ExecuteActivity(childCtx, string(currentNode.Type), activityArgs(currentNode, docking)...).Get(childCtx, &activityResult)
 
 			// clean up cancle function
 			cancelCurrentActivity = nil
 			cancel()
 
+			recordNodeExecution(ctx, pkg.NodeExecutionEvent{
+				RunID:     runID,
+				NodeID:    currentNodeID,
+				Type:      currentNode.Type,
+				Status:    executionStatus(err),
+				Result:    activityResult,
+				Error:     errorMessage(err),
+				StartTime: startTime,
+				EndTime:   workflow.Now(ctx),
+			})
+
 			if temporal.IsCanceledError(err) {
-				workflowID := workflow.GetInfo(ctx).WorkflowExecution.ID
-				logger.Info("Activity was cancelled due to pause signal", "workflowID", workflowID, "activityType", string(currentNode.Type))
+				if ctx.Err() != nil {
+					return "", temporal.NewCanceledError()
+				}
+				logger.Info("Activity was cancelled due to pause signal", "activity_type", string(currentNode.Type))
 				currentStep = "Paused"
 				continue
 			}
@@ -130,17 +208,89 @@ func RobotWorkflow(ctx workflow.Context, payload pkg.WorkflowPayload) (string, e
 				return "", fmt.Errorf("invalid or missing duration parameter for sleep activity")
 			}
 			duration := int(durationFloat)
+			startTime := workflow.Now(ctx)
 			err := workflow.Sleep(ctx, time.Millisecond*time.Duration(duration))
 
 			cancelCurrentActivity = nil
 			cancel()
 
+			recordNodeExecution(ctx, pkg.NodeExecutionEvent{
+				RunID:     runID,
+				NodeID:    currentNodeID,
+				Type:      currentNode.Type,
+				Status:    executionStatus(err),
+				Error:     errorMessage(err),
+				StartTime: startTime,
+				EndTime:   workflow.Now(ctx),
+			})
+
 			if temporal.IsCanceledError(err) {
+				if ctx.Err() != nil {
+					return "", temporal.NewCanceledError()
+				}
 				logger.Info("Sleep activity was cancelled due to pause signal")
 				continue
 			}
 
 			// Move to next node
+			if currentNode.Transitions.Next == "" {
+				logger.Info("Workflow completed successfully")
+				return "Workflow completed successfully", nil
+			}
+			currentNodeID = currentNode.Transitions.Next
+		case pkg.ActivityWaitForSignal:
+			signalName, ok := currentNode.Params["signal"].(string)
+			if !ok || signalName == "" || signalName == ControlSignalName {
+				cancel()
+				return "", fmt.Errorf("invalid or missing signal parameter for wait for signal node")
+			}
+			// timeout in milliseconds, zero or missing waits forever
+			timeoutFloat, _ := currentNode.Params["timeout"].(float64)
+
+			startTime := workflow.Now(ctx)
+			waitingSignal = signalName
+			signalPayload, err := waitForSignal(childCtx, signalName, time.Millisecond*time.Duration(timeoutFloat))
+			waitingSignal = ""
+
+			cancelCurrentActivity = nil
+			cancel()
+
+			recordNodeExecution(ctx, pkg.NodeExecutionEvent{
+				RunID:     runID,
+				NodeID:    currentNodeID,
+				Type:      currentNode.Type,
+				Status:    executionStatus(err),
+				Error:     errorMessage(err),
+				StartTime: startTime,
+				EndTime:   workflow.Now(ctx),
+			})
+
+			if temporal.IsCanceledError(err) {
+				if ctx.Err() != nil {
+					return "", temporal.NewCanceledError()
+				}
+				logger.Info("Wait for signal was cancelled due to pause signal", "signal", signalName)
+				continue
+			}
+
+			if err != nil {
+				logger.Error("Wait for signal failed", "signal", signalName, "error", err)
+				if currentNode.Transitions.Failure == "" {
+					return "", fmt.Errorf("no failure transition defined for node %s", currentNodeID)
+				}
+				currentNodeID = currentNode.Transitions.Failure
+				continue
+			}
+
+			// store received payload, keyed by the "variable" param or the signal name
+			if signalPayload != nil {
+				variable, _ := currentNode.Params["variable"].(string)
+				if variable == "" {
+					variable = signalName
+				}
+				variables[variable] = signalPayload
+			}
+
 			if currentNode.Transitions.Next == "" {
 				logger.Info("Workflow completed successfully")
 				return "Workflow completed successfully", nil
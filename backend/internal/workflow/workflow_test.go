@@ -0,0 +1,174 @@
+package workflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chungweeeei/Temporal-robot-project/pkg"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+)
+
+// recorded collects the events of the recording activities.
+type recorded struct {
+	starts []pkg.ExecutionEvent
+	ends   []pkg.ExecutionEvent
+	nodes  []pkg.NodeExecutionEvent
+}
+
+// newTestEnv registers the recording activities and robot activities running robotActivity.
+func newTestEnv(t *testing.T, robotActivity func(ctx context.Context, params map[string]interface{}) (string, error)) (*testsuite.TestWorkflowEnvironment, *recorded) {
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	// a cancel reaches a running activity with its throttled heartbeat, seconds after the request
+	env.SetTestTimeout(30 * time.Second)
+	records := &recorded{}
+
+	env.RegisterActivityWithOptions(func(ctx context.Context, event pkg.ExecutionEvent) error {
+		records.starts = append(records.starts, event)
+		return nil
+	}, activity.RegisterOptions{Name: "RecordExecutionStart"})
+	env.RegisterActivityWithOptions(func(ctx context.Context, event pkg.ExecutionEvent) error {
+		records.ends = append(records.ends, event)
+		return nil
+	}, activity.RegisterOptions{Name: "RecordExecutionEnd"})
+	env.RegisterActivityWithOptions(func(ctx context.Context, event pkg.NodeExecutionEvent) error {
+		records.nodes = append(records.nodes, event)
+		return nil
+	}, activity.RegisterOptions{Name: "RecordNodeExecution"})
+	env.RegisterActivityWithOptions(robotActivity, activity.RegisterOptions{Name: string(pkg.ActivityTTS)})
+	env.RegisterWorkflow(RobotWorkflow)
+
+	return env, records
+}
+
+// flow is start -> node -> end, the node's failure transition ends the run with an error.
+func flow(node pkg.WorkflowNode) pkg.WorkflowPayload {
+	node.ID = "node"
+	node.Transitions = pkg.WorkflowTransitions{Next: "end"}
+	return pkg.WorkflowPayload{
+		WorkflowID: "test-workflow",
+		RootNodeID: "start",
+		Nodes: map[string]pkg.WorkflowNode{
+			"start": {ID: "start", Type: pkg.ActivityStart, Transitions: pkg.WorkflowTransitions{Next: "node"}},
+			"node":  node,
+			"end":   {ID: "end", Type: pkg.ActivityEnd},
+		},
+	}
+}
+
+func say(ctx context.Context, params map[string]interface{}) (string, error) {
+	return "said", nil
+}
+
+// untilCancelled heartbeats until the activity is cancelled, the cancel arrives with a heartbeat.
+func untilCancelled(ctx context.Context, params map[string]interface{}) (string, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+			activity.RecordHeartbeat(ctx)
+		}
+	}
+}
+
+func TestCancelWorkflow(t *testing.T) {
+
+	tests := []struct {
+		name string
+		node pkg.WorkflowNode
+	}{
+		{"during sleep", pkg.WorkflowNode{Type: pkg.ActivitySleep, Params: map[string]interface{}{"duration": float64(time.Hour.Milliseconds())}}},
+		{"during wait for signal", pkg.WorkflowNode{Type: pkg.ActivityWaitForSignal, Params: map[string]interface{}{"signal": "operator_ack"}}},
+		{"during activity", pkg.WorkflowNode{Type: pkg.ActivityTTS, Params: map[string]interface{}{"text": "hello"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			env, _ := newTestEnv(t, untilCancelled)
+			env.RegisterDelayedCallback(env.CancelWorkflow, time.Second)
+
+			env.ExecuteWorkflow(RobotWorkflow, flow(tt.node))
+
+			if !env.IsWorkflowCompleted() {
+				t.Fatal("workflow did not complete")
+			}
+			if err := env.GetWorkflowError(); !temporal.IsCanceledError(err) {
+				t.Fatalf("workflow error = %v, want canceled", err)
+			}
+		})
+	}
+}
+
+func TestPauseResumeWorkflow(t *testing.T) {
+
+	attempts := 0
+	env, _ := newTestEnv(t, func(ctx context.Context, params map[string]interface{}) (string, error) {
+		attempts++
+		if attempts > 1 {
+			return say(ctx, params)
+		}
+		return untilCancelled(ctx, params)
+	})
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(ControlSignalName, pkg.ControlSignal{Action: "pause"})
+	}, time.Second)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(ControlSignalName, pkg.ControlSignal{Action: "resume"})
+	}, time.Minute)
+
+	env.ExecuteWorkflow(RobotWorkflow, flow(pkg.WorkflowNode{Type: pkg.ActivityTTS, Params: map[string]interface{}{"text": "hello"}}))
+
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatalf("workflow error = %v, want completed after resume", err)
+	}
+	if attempts != 2 {
+		t.Errorf("activity ran %d times, want 2", attempts)
+	}
+}
+
+func TestExecutionRecords(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		robotActivity func(ctx context.Context, params map[string]interface{}) (string, error)
+		cancel        bool
+		want          pkg.ExecutionStatus
+	}{
+		{"completed", say, false, pkg.ExecutionCompleted},
+		{"failed", func(ctx context.Context, params map[string]interface{}) (string, error) {
+			return "", temporal.NewNonRetryableApplicationError("speaker unavailable", "TTSFailed", nil)
+		}, false, pkg.ExecutionFailed},
+		{"canceled", untilCancelled, true, pkg.ExecutionCanceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			env, records := newTestEnv(t, tt.robotActivity)
+			if tt.cancel {
+				env.RegisterDelayedCallback(env.CancelWorkflow, time.Second)
+			}
+
+			env.ExecuteWorkflow(RobotWorkflow, flow(pkg.WorkflowNode{Type: pkg.ActivityTTS, Params: map[string]interface{}{"text": "hello"}}))
+			if !env.IsWorkflowCompleted() {
+				t.Fatal("workflow did not complete")
+			}
+
+			if len(records.starts) != 1 || records.starts[0].Status != pkg.ExecutionRunning || records.starts[0].WorkflowID != "test-workflow" {
+				t.Errorf("start records = %+v, want one running record of test-workflow", records.starts)
+			}
+			if len(records.nodes) != 1 || records.nodes[0].NodeID != "node" || records.nodes[0].Status != tt.want {
+				t.Errorf("node records = %+v, want one %s record of node", records.nodes, tt.want)
+			}
+			if len(records.ends) != 1 || records.ends[0].Status != tt.want {
+				t.Errorf("end records = %+v, want one %s record", records.ends, tt.want)
+			}
+		})
+	}
+}
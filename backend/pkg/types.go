@@ -1,5 +1,7 @@
 package pkg
 
+import "time"
+
 type RobotServiceRequest struct {
 	Op      string `json:"op"`
 	Service string `json:"service"`
@@ -19,6 +21,7 @@ type RobotServiceResponse struct {
 
 type RobotTopicRequest struct {
 	Op           string `json:"op"`
+	ID           string `json:"id,omitempty"`
 	Topic        string `json:"topic"`
 	Type         string `json:"type"`
 	ThrottleRate int    `json:"throttle_rate"`
@@ -36,14 +39,19 @@ type RobotTopicResponse struct {
 type ActivityType string
 
 const (
-	ActivityStandUp ActivityType = "Standup"
-	ActivitySitDown ActivityType = "Sitdown"
-	ActivityHead    ActivityType = "Head"
-	ActivityMove    ActivityType = "Move"
-	ActivityTTS     ActivityType = "TTS"
-	ActivitySleep   ActivityType = "Sleep"
-	ActivityStart   ActivityType = "Start"
-	ActivityEnd     ActivityType = "End"
+	ActivityStandUp   ActivityType = "Standup"
+	ActivityStandDown ActivityType = "Standdown"
+	ActivitySitDown   ActivityType = "Sitdown"
+	ActivityHead      ActivityType = "Head"
+	ActivityMove      ActivityType = "Move"
+	ActivityTTS       ActivityType = "TTS"
+	ActivityDock      ActivityType = "Dock"
+	ActivityCharge    ActivityType = "Charge"
+	ActivitySleep     ActivityType = "Sleep"
+	ActivityStart     ActivityType = "Start"
+	ActivityEnd       ActivityType = "End"
+
+	ActivityWaitForSignal ActivityType = "WaitForSignal"
 )
 
 type WorkflowTransitions struct {
@@ -58,8 +66,58 @@ type WorkflowNode struct {
 	Transitions WorkflowTransitions    `json:"transitions"`
 }
 
+// BatteryPolicy interrupts the flow and jumps to the return-to-dock sub-flow
+// once the battery drops to CriticalLevel.
+type BatteryPolicy struct {
+	CriticalLevel      int    `json:"critical_level"`
+	ReturnToDockNodeID string `json:"return_to_dock_node_id"`
+}
+
+// MoveOptions are set by the workflow next to the node params of a Move.
+type MoveOptions struct {
+	// SkipBatteryGuard lets the return-to-dock sub-flow move on a battery the guard would refuse.
+	SkipBatteryGuard bool `json:"skip_battery_guard,omitempty"`
+}
+
 type WorkflowPayload struct {
-	WorkflowID string                  `json:"workflow_id,omitempty"`
-	RootNodeID string                  `json:"root_node_id,omitempty"`
-	Nodes      map[string]WorkflowNode `json:"nodes"`
+	WorkflowID    string                  `json:"workflow_id,omitempty"`
+	ScheduleID    string                  `json:"schedule_id,omitempty"`
+	RootNodeID    string                  `json:"root_node_id,omitempty"`
+	Nodes         map[string]WorkflowNode `json:"nodes"`
+	BatteryPolicy *BatteryPolicy          `json:"battery_policy,omitempty"`
+}
+
+// ControlSignal pauses or resumes a running workflow, Actor is the caller who sent it.
+type ControlSignal struct {
+	Action string `json:"action"`
+	Actor  string `json:"actor,omitempty"`
+}
+
+type ExecutionStatus string
+
+const (
+	ExecutionRunning   ExecutionStatus = "Running"
+	ExecutionCompleted ExecutionStatus = "Completed"
+	ExecutionFailed    ExecutionStatus = "Failed"
+	ExecutionCanceled  ExecutionStatus = "Canceled"
+)
+
+type ExecutionEvent struct {
+	RunID      string          `json:"run_id"`
+	WorkflowID string          `json:"workflow_id"`
+	ScheduleID string          `json:"schedule_id,omitempty"`
+	Status     ExecutionStatus `json:"status"`
+	Error      string          `json:"error,omitempty"`
+	Time       time.Time       `json:"time"`
+}
+
+type NodeExecutionEvent struct {
+	RunID     string          `json:"run_id"`
+	NodeID    string          `json:"node_id"`
+	Type      ActivityType    `json:"type"`
+	Status    ExecutionStatus `json:"status"`
+	Result    string          `json:"result,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	StartTime time.Time       `json:"start_time"`
+	EndTime   time.Time       `json:"end_time"`
 }
@@ -1,39 +1,122 @@
 package utils
 
-import "strconv"
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
 
 func ToInt(v interface{}) int {
+	i, _ := TryInt(v)
+	return i
+}
+
+func ToFloat(v interface{}) float64 {
+	f, _ := TryFloat(v)
+	return f
+}
+
+func ToBool(v interface{}) bool {
+	b, _ := TryBool(v)
+	return b
+}
+
+func ToString(v interface{}) string {
+	s, _ := TryString(v)
+	return s
+}
+
+// TryInt converts like ToInt and reports values that are not a number, nil gives 0 without error.
+func TryInt(v interface{}) (int, error) {
 
 	if v == nil {
-		return 0
+		return 0, nil
 	}
 
 	switch val := v.(type) {
 	case float64:
-		return int(val)
+		return int(val), nil
 	case int:
-		return val
+		return val, nil
 	case string:
-		i, _ := strconv.Atoi(val)
-		return i
+		if i, err := strconv.Atoi(val); err == nil {
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return 0, fmt.Errorf("%q is not a number", val)
+		}
+		return int(f), nil
 	default:
-		return 0
+		return 0, fmt.Errorf("unexpected type %T", v)
 	}
 }
 
-func ToFloat(v interface{}) float64 {
+// TryFloat converts like ToFloat and reports values that are not a number, nil gives 0 without error.
+func TryFloat(v interface{}) (float64, error) {
+
+	if v == nil {
+		return 0, nil
+	}
+
+	switch val := v.(type) {
+	case float64:
+		return val, nil
+	case int:
+		return float64(val), nil
+	case string:
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a number", val)
+		}
+		return f, nil
+	default:
+		return 0, fmt.Errorf("unexpected type %T", v)
+	}
+}
+
+// TryBool converts like ToBool and reports values that are not a boolean, nil gives false without error.
+func TryBool(v interface{}) (bool, error) {
+
 	if v == nil {
-		return 0
+		return false, nil
 	}
+
 	switch val := v.(type) {
+	case bool:
+		return val, nil
 	case float64:
-		return val
+		return val != 0, nil
 	case int:
-		return float64(val)
+		return val != 0, nil
+	case string:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return false, fmt.Errorf("%q is not a boolean", val)
+		}
+		return b, nil
+	default:
+		return false, fmt.Errorf("unexpected type %T", v)
+	}
+}
+
+// TryString converts like ToString and reports values that are not a scalar, nil gives "" without error.
+func TryString(v interface{}) (string, error) {
+
+	if v == nil {
+		return "", nil
+	}
+
+	switch val := v.(type) {
 	case string:
-		f, _ := strconv.ParseFloat(val, 64)
-		return f
+		return val, nil
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
+	case int:
+		return strconv.Itoa(val), nil
+	case bool:
+		return strconv.FormatBool(val), nil
 	default:
-		return 0
+		return "", fmt.Errorf("unexpected type %T", v)
 	}
 }
@@ -0,0 +1,28 @@
+export interface TelemetrySample {
+  robot_id: string;
+  time: string;
+  x: number;
+  y: number;
+  orientation: number;
+  battery_level: number;
+  charging: boolean;
+  docked: boolean;
+  mission_id: string;
+  mission_code: number;
+}
+
+export interface RobotTelemetry {
+  robot_id: string;
+  from: string;
+  to: string;
+  samples: TelemetrySample[];
+}
+
+export interface PoseTrail {
+  run_id: string;
+  robot_id: string;
+  status: string;
+  start_time: string;
+  end_time: string;
+  trail: TelemetrySample[];
+}
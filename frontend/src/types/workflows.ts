@@ -1,4 +1,4 @@
-export type ActivityType = "Standup" | "Standdown" | "Sitdown" | "Move" | "Sleep" | "Start" | "End" | "TTS" | "Head";
+export type ActivityType = "Standup" | "Standdown" | "Sitdown" | "Move" | "Sleep" | "Start" | "End" | "TTS" | "Head" | "Dock" | "Charge" | "WaitForSignal";
 export type WorkflowStatusDef = "Idle" | "Running" | "Completed" | "Failed" | "Paused" | "Canceled" | "Terminated";
 
 export interface BaseParams {
@@ -23,6 +23,16 @@ export interface TTSParams extends BaseParams {
   text: string; // text to speak
 }
 
+export interface ChargeParams extends BaseParams {
+  target: number; // battery percentage to charge up to
+}
+
+export interface WaitForSignalParams extends BaseParams {
+  signal: string;    // signal name to wait for
+  timeout: number;   // milliseconds, 0 waits forever
+  variable: string;  // workflow variable for the signal payload
+}
+
 export interface NodeInfo {
     id: string;
     type: ActivityType | "Start" | "End";
@@ -33,11 +43,17 @@ export interface NodeInfo {
     };
 }
 
+export interface BatteryPolicy {
+    critical_level: number;
+    return_to_dock_node_id: string;
+}
+
 export interface WorkflowInfo {
     workflow_id: string;
     workflow_name: string;
     root_node_id: string;
     nodes: Record<string, NodeInfo>;
+    battery_policy?: BatteryPolicy | null;
     created_at: number;
     updated_at: number;
 }
@@ -46,6 +62,7 @@ export interface WorkflowStatus {
     status: WorkflowStatusDef;
     current_node: string;
     current_step: string;
+    waiting_signal?: string;
 }
 
 export interface WorkflowExecution {
@@ -59,8 +76,12 @@ export interface WorkflowExecution {
 
 export interface WorkflowRecord {
   workflow_id: string;
+  workflow_name: string;
+  schedule_id?: string;
+  robot_id?: string;
   run_id: string;
   status: WorkflowStatusDef;
+  error?: string;
   start_time: string;
   end_time?: string;
 }
\ No newline at end of file
@@ -1,3 +1,10 @@
+import axios from "axios";
 import { QueryClient } from "@tanstack/react-query";
 
 export const queryClient = new QueryClient();
+
+// The REST server requires credentials, create a key with `rest-server -create-api-key <name>`
+const apiKey = import.meta.env.VITE_API_KEY;
+if (apiKey) {
+  axios.defaults.headers.common["X-API-Key"] = apiKey;
+}